@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -16,13 +17,15 @@ const (
 	ActionIngameReset int = iota
 	ActionIngameFocus
 	ActionIngameRes
+	ActionIngameMeasure
 )
 
 // Mapping of action names -> action types
 var actionNames = map[string]int{
-	"ingame_reset":      ActionIngameReset,
-	"ingame_focus":      ActionIngameFocus,
-	"ingame_toggle_res": ActionIngameRes,
+	"ingame_reset":          ActionIngameReset,
+	"ingame_focus":          ActionIngameFocus,
+	"ingame_toggle_res":     ActionIngameRes,
+	"ingame_toggle_measure": ActionIngameMeasure,
 }
 
 // Keybind parsing regexes
@@ -51,6 +54,9 @@ type Bind struct {
 	Mods     [4]xproto.Keycode // The list of key modifiers for this keybind (if any.)
 	ModCount int               // The number of modifiers in use.
 
+	// The name of the key, if it was specified by name.
+	keyName string
+
 	// String representation.
 	str string
 }
@@ -114,6 +120,39 @@ func (a *ActionList) UnmarshalTOML(value any) error {
 	return nil
 }
 
+// String implements Stringer.
+func (a Action) String() string {
+	for name, typ := range actionNames {
+		if typ != a.Type {
+			continue
+		}
+		if a.Extra != nil {
+			// Add 1 to undo 0-based indexing.
+			return fmt.Sprintf("%s(%d)", name, *a.Extra+1)
+		}
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", a.Type)
+}
+
+// Describe returns a normalized, human-readable description of the keys and
+// buttons which make up the bind. Modifiers are sorted by name, so binds which
+// only differ in the order of their modifiers have the same description.
+func (b *Bind) Describe() string {
+	var parts []string
+	for _, mod := range b.Mods[:b.ModCount] {
+		parts = append(parts, x11.KeyName(mod))
+	}
+	sort.Strings(parts)
+	if b.Key != nil {
+		parts = append(parts, x11.KeyName(*b.Key))
+	}
+	if b.Button != nil {
+		parts = append(parts, x11.ButtonName(*b.Button))
+	}
+	return strings.Join(parts, "-")
+}
+
 // String implements Stringer.
 func (b *Bind) String() string {
 	return b.str
@@ -135,6 +174,7 @@ func (b *Bind) UnmarshalTOML(value any) error {
 				return errors.New("more than one key")
 			}
 			b.Key = &key
+			b.keyName = split
 		} else if mod, ok := x11.Modifiers[split]; ok {
 			if b.ModCount == 4 {
 				return errors.New("too many modifiers (max of 4)")
@@ -233,6 +273,20 @@ func (a *NormalResHook) UnmarshalTOML(value any) error {
 	return nil
 }
 
+// WithLayout returns a copy of the keybinds with each key which was specified
+// by name resolved according to the given keyboard layout (a mapping of key
+// names to keycodes.) Keys which are not in the layout are left unchanged.
+func (k Keybinds) WithLayout(layout map[string]xproto.Keycode) Keybinds {
+	binds := make(Keybinds, len(k))
+	for bind, actions := range k {
+		if code, ok := layout[bind.keyName]; ok {
+			bind.Key = &code
+		}
+		binds[bind] = actions
+	}
+	return binds
+}
+
 // UnmarshalTOML implements toml.Unmarshaler.
 func (k *Keybinds) UnmarshalTOML(value any) error {
 	m, ok := value.(map[string]any)
@@ -0,0 +1,52 @@
+package cfg
+
+import (
+	"testing"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+func TestBindDescribe(t *testing.T) {
+	tests := []struct {
+		bind string
+		want string
+	}{
+		{"D", "d"},
+		{"Ctrl-Shift-D", "ctrl-shift-d"},
+		{"Shift-Ctrl-D", "ctrl-shift-d"},
+		{"LControl-Alt-Mouse5", "alt-ctrl-m5"},
+	}
+	for _, tt := range tests {
+		var bind Bind
+		if err := bind.UnmarshalTOML(tt.bind); err != nil {
+			t.Fatalf("parse %q: %s", tt.bind, err)
+		}
+		if got := bind.Describe(); got != tt.want {
+			t.Errorf("Describe(%q) = %q, want %q", tt.bind, got, tt.want)
+		}
+	}
+}
+
+func TestKeybindsWithLayout(t *testing.T) {
+	var binds Keybinds
+	err := binds.UnmarshalTOML(map[string]any{
+		"Ctrl-A": []any{"ingame_reset"},
+		"F1":     []any{"ingame_focus"},
+	})
+	if err != nil {
+		t.Fatalf("parse keybinds: %s", err)
+	}
+
+	// On AZERTY, "a" is where "q" is on QWERTY.
+	layout := map[string]xproto.Keycode{"a": 24, "q": 38}
+	want := map[string]xproto.Keycode{"Ctrl-A": 24, "F1": 67}
+	got := binds.WithLayout(layout)
+	if len(got) != len(binds) {
+		t.Fatalf("got %d binds, want %d", len(got), len(binds))
+	}
+	for bind := range got {
+		if *bind.Key != want[bind.String()] {
+			t.Errorf("bind %q: got keycode %d, want %d", bind.String(), *bind.Key, want[bind.String()])
+		}
+	}
+}
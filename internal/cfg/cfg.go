@@ -6,12 +6,39 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/tesselslate/resetti/internal/log"
 	"github.com/tesselslate/resetti/internal/res"
 )
 
+// Maximum time (in milliseconds) to hold the reset key for. resetti does not
+// handle any other input while the key is held.
+const maxResetHold = 1000
+
+// Default focus debounce (in milliseconds) for profiles which do not set one.
+const defaultFocusDebounce = 50
+
+// Hook types
+const (
+	HookReset int = iota
+	HookAltRes
+	HookNormalRes
+	HookFocusLost
+	HookFocusGained
+)
+
+// Mapping of hook names -> hook types
+var HookNames = map[string]int{
+	"reset":        HookReset,
+	"alt_res":      HookAltRes,
+	"normal_res":   HookNormalRes,
+	"focus_lost":   HookFocusLost,
+	"focus_gained": HookFocusGained,
+}
+
 // Hooks contains various commands to run whenever the user performs certain
 // actions.
 type Hooks struct {
@@ -20,6 +47,16 @@ type Hooks struct {
 	NormalRes   NormalResHook `toml:"normal_res"`   // Command to run on normal resolution
 	FocusLost   string        `toml:"focus_lost"`   // Command to run when instance loses focus
 	FocusGained string        `toml:"focus_gained"` // Command to run when instance gains focus
+
+	Sync    []string `toml:"sync"`    // Names of hooks to run synchronously
+	Timeout int      `toml:"timeout"` // Timeout for synchronous hooks, in milliseconds
+}
+
+// Limits contains thresholds for resetti's own resource usage. A warning is
+// logged when any of them is exceeded. Zero values are ignored.
+type Limits struct {
+	Memory     int `toml:"memory"`     // Memory usage in MB
+	Goroutines int `toml:"goroutines"` // Number of goroutines
 }
 
 // Keybinds contains the user's keybindings.
@@ -27,12 +64,27 @@ type Keybinds map[Bind]ActionList
 
 // Profile contains an entire configuration profile.
 type Profile struct {
-	PollRate  int        `toml:"poll_rate"` // Polling rate for input handling
-	NormalRes *Rectangle `toml:"play_res"`  // Normal resolution
-	AltRes    AltRes     `toml:"alt_res"`   // Alternate ingame resolution
+	Display   string     `toml:"display"`     // X display to connect to
+	PollRate  int        `toml:"poll_rate"`   // Polling rate for input handling
+	NormalRes *Rectangle `toml:"play_res"`    // Normal resolution
+	AltRes    AltRes     `toml:"alt_res"`     // Alternate ingame resolution
+	StartRes  *Rectangle `toml:"startup_res"` // Resolution to set on startup
+
+	ResetSound string `toml:"reset_sound"`    // Command to play a sound on reset
+	SafeInput  bool   `toml:"safe_input"`     // Only send the inputs needed to reset
+	ResetStart bool   `toml:"reset_on_start"` // Reset the instance on startup
+	ResetHold  int    `toml:"reset_hold"`     // Time to hold the reset key, in milliseconds
+
+	ExitFullscreen bool `toml:"exit_fullscreen"` // Exit fullscreen to change resolution
+	ClampRes       bool `toml:"clamp_res"`       // Clamp resolutions to the screen size
+	FocusDebounce  int  `toml:"focus_debounce"`  // Time for focus to settle, in milliseconds
+
+	LiveSplit string `toml:"livesplit"` // Address of the LiveSplit server
+	Metrics   string `toml:"metrics"`   // Address to serve metrics on
 
 	Hooks    Hooks    `toml:"hooks"`
 	Keybinds Keybinds `toml:"keybinds"`
+	Limits   Limits   `toml:"limits"`
 }
 
 // Rectangle is a rectangle. That's it.
@@ -52,6 +104,27 @@ func GetDirectory() (string, error) {
 	return xdgDir + "/resetti/", nil
 }
 
+// GetProfiles returns the names of all of the user's configuration profiles.
+func GetProfiles() ([]string, error) {
+	dir, err := GetDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("get config directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read config directory: %w", err)
+	}
+	var profiles []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".toml")
+		if ok && !entry.IsDir() {
+			profiles = append(profiles, name)
+		}
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
 // GetProfile returns a parsed configuration profile.
 func GetProfile(name string) (Profile, error) {
 	dir, err := GetDirectory()
@@ -60,9 +133,16 @@ func GetProfile(name string) (Profile, error) {
 	}
 	file, err := os.ReadFile(dir + name + ".toml")
 	if err != nil {
+		if os.IsNotExist(err) {
+			return Profile{}, fmt.Errorf("profile %q does not exist in %s", name, dir)
+		}
 		return Profile{}, fmt.Errorf("read config file: %w", err)
 	}
-	profile := Profile{}
+	// Options which were added after profiles were first created are given
+	// their defaults here, since older profiles will not have them.
+	profile := Profile{
+		FocusDebounce: defaultFocusDebounce,
+	}
 	if err = toml.Unmarshal(file, &profile); err != nil {
 		return Profile{}, fmt.Errorf("parse config file: %w", err)
 	}
@@ -110,10 +190,20 @@ func validateProfile(conf *Profile) error {
 		log.Warn("Very low poll rate in config. Consider increasing.")
 	}
 
+	if conf.FocusDebounce < 0 {
+		return errors.New("invalid focus debounce")
+	}
+	if conf.ResetHold < 0 || conf.ResetHold > maxResetHold {
+		return fmt.Errorf("invalid reset key hold time (must be 0-%d)", maxResetHold)
+	}
+
 	// Check resolution settings.
 	if !validateRectangle(conf.NormalRes) {
 		return errors.New("invalid playing resolution")
 	}
+	if !validateRectangle(conf.StartRes) {
+		return errors.New("invalid startup resolution")
+	}
 	for idx, res := range conf.AltRes {
 		if !validateRectangle(&res) {
 			if len(conf.AltRes) == 1 {
@@ -129,6 +219,20 @@ func validateProfile(conf *Profile) error {
 		return errors.New("need both alternate and playing resolution")
 	}
 
+	if conf.Limits.Memory < 0 || conf.Limits.Goroutines < 0 {
+		return errors.New("invalid resource limits")
+	}
+
+	// Check synchronous hook settings.
+	for _, name := range conf.Hooks.Sync {
+		if _, ok := HookNames[name]; !ok {
+			return fmt.Errorf("invalid synchronous hook %q", name)
+		}
+	}
+	if len(conf.Hooks.Sync) > 0 && conf.Hooks.Timeout <= 0 {
+		return errors.New("invalid hook timeout")
+	}
+
 	return nil
 }
 
@@ -150,6 +254,28 @@ func validateRectangle(r *Rectangle) bool {
 	return r == nil || r.W > 0 && r.H > 0
 }
 
+// Clamp returns the rectangle, moved and shrunk as needed to fit within a
+// screen of the given size.
+func (r Rectangle) Clamp(w, h uint32) Rectangle {
+	if r.W > w {
+		r.W = w
+	}
+	if r.H > h {
+		r.H = h
+	}
+	if r.X < 0 {
+		r.X = 0
+	} else if uint32(r.X)+r.W > w {
+		r.X = int32(w - r.W)
+	}
+	if r.Y < 0 {
+		r.Y = 0
+	} else if uint32(r.Y)+r.H > h {
+		r.Y = int32(h - r.H)
+	}
+	return r
+}
+
 // UnmarshalTOML implements toml.Unmarshaler.
 func (r *Rectangle) UnmarshalTOML(value any) error {
 	str, ok := value.(string)
@@ -0,0 +1,29 @@
+package cfg
+
+import "testing"
+
+func TestRectangleClamp(t *testing.T) {
+	const w, h = 1920, 1080
+	tests := []struct {
+		name string
+		rect Rectangle
+		want Rectangle
+	}{
+		{"Fits", Rectangle{0, 0, 1920, 1080}, Rectangle{0, 0, 1920, 1080}},
+		{"FitsInside", Rectangle{810, 0, 300, 1080}, Rectangle{810, 0, 300, 1080}},
+		{"TooTall", Rectangle{810, 0, 300, 16384}, Rectangle{810, 0, 300, 1080}},
+		{"TooWide", Rectangle{0, 400, 3840, 300}, Rectangle{0, 400, 1920, 300}},
+		{"OffRight", Rectangle{1800, 0, 300, 1080}, Rectangle{1620, 0, 300, 1080}},
+		{"OffBottom", Rectangle{0, 900, 1920, 300}, Rectangle{0, 780, 1920, 300}},
+		{"NegativeX", Rectangle{-100, 0, 300, 1080}, Rectangle{0, 0, 300, 1080}},
+		{"NegativeY", Rectangle{0, -8000, 60, 16384}, Rectangle{0, 0, 60, 1080}},
+		{"TooBigAndOffset", Rectangle{500, 500, 4000, 4000}, Rectangle{0, 0, 1920, 1080}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rect.Clamp(w, h); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -27,30 +28,35 @@ const boatOnCmd = "./boat_on.sh"
 const boatOffCmd = "./boat_off.sh"
 var boateyeEnabled = false
 
-// Hook types
-const (
-	HookReset int = iota
-	HookAltRes
-	HookNormalRes
-	HookFocusLost
-	HookFocusGained
-)
-
 // Controller manages all of the components necessary for resetti to run and
 // handles communication between them.
 type Controller struct {
 	conf *cfg.Profile
 	dbg  *debugLogger
-	x    *x11.Client
+	x    x11.Conn
 
 	manager  *mc.Manager
 	frontend Frontend
+	split    *liveSplit
 
 	binds    map[cfg.Bind]cfg.ActionList
 	inputMgr inputManager
 	inputs   <-chan Input
 	hooks    map[int][]string
 
+	// Hooks which should be run synchronously (see cfg.Hooks.Sync.)
+	syncHooks map[int]bool
+
+	// Session statistics. resets and lastReset (Unix time in nanoseconds) may
+	// be read from other goroutines (e.g. the metrics server.)
+	start     time.Time
+	resets    atomic.Int64
+	lastReset atomic.Int64
+
+	// Whether or not a reset sound is currently playing. Used to avoid
+	// spawning a new sound process for every reset in quick succession.
+	soundPlaying atomic.Bool
+
 	x11Events <-chan x11.Event
 	x11Errors <-chan error
 	signals   <-chan os.Signal
@@ -81,7 +87,7 @@ type Input struct {
 // need to setup and run.
 type frontendDependencies struct {
 	conf     *cfg.Profile
-	x        *x11.Client
+	x        x11.Conn
 	instance mc.InstanceInfo
 	host     *Controller
 }
@@ -90,10 +96,15 @@ type frontendDependencies struct {
 // they are pressing any hotkeys.
 type inputManager struct {
 	conf *cfg.Profile
-	x    *x11.Client
+	x    x11.Conn
 
 	lastBinds      []cfg.Bind    // The keybinds pressed during the last query.
 	lastFailWindow xproto.Window // The last window QueryPointer failed on.
+
+	// The mutex guards binds, which are replaced whenever the keyboard layout
+	// changes.
+	mu    sync.Mutex
+	binds cfg.Keybinds
 }
 
 // Run creates a new controller with the given configuration profile and runs it.
@@ -107,22 +118,23 @@ func Run(conf *cfg.Profile) error {
 	c := Controller{}
 	c.dbg = &debugLogger{&c}
 	c.conf = conf
+	c.start = time.Now()
 	c.binds = make(map[cfg.Bind]cfg.ActionList)
-	c.hooks = map[int][]string{
-		HookReset:       {c.conf.Hooks.Reset},
-		HookAltRes:      c.conf.Hooks.AltRes,
-		HookNormalRes:   c.conf.Hooks.NormalRes,
-		HookFocusLost:   {c.conf.Hooks.FocusLost},
-		HookFocusGained: {c.conf.Hooks.FocusGained},
-	}
+	c.loadHooks()
 
-	x, err := x11.NewClient()
+	x, err := x11.NewClient(conf.Display)
 	if err != nil {
 		return fmt.Errorf("(init) create X client: %w", err)
 	}
 	c.x = &x
+	if err := c.applyLayout(); err != nil {
+		log.Warn("Failed to get keyboard layout: %s", err)
+	}
+	if err := c.grabButtons(); err != nil {
+		return fmt.Errorf("(init) grab buttons: %w", err)
+	}
 
-	instance, err := mc.FindInstance(&x)
+	instance, err := mc.FindInstance(c.x)
 	if err != nil {
 		return fmt.Errorf("(init) find instance: %w", err)
 	}
@@ -131,12 +143,32 @@ func Run(conf *cfg.Profile) error {
 	} else {
 		log.Info("Instance detected does not have modern WorldPreview")
 	}
+	if err := c.checkResolutions(instance.Wid); err != nil {
+		log.Warn("Failed to check resolutions: %s", err)
+	}
 
-	c.manager, err = mc.NewManager(instance, conf, &x)
+	c.manager, err = mc.NewManager(instance, conf, c.x)
 	if err != nil {
 		return fmt.Errorf("(init) create manager: %w", err)
 	}
 
+	if conf.LiveSplit != "" {
+		c.split = newLiveSplit(conf.LiveSplit)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.split.Run(ctx)
+		}()
+	}
+
+	if conf.Metrics != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.serveMetrics(ctx, conf.Metrics)
+		}()
+	}
+
 	c.frontend = &Single{}
 
 	// Start various components
@@ -149,13 +181,21 @@ func Run(conf *cfg.Profile) error {
 	if err != nil {
 		return fmt.Errorf("(init) setup frontend: %w", err)
 	}
+	if conf.ResetStart && c.ResetInstance() {
+		log.Info("Reset instance on startup.")
+		c.RunHook(cfg.HookReset, 0)
+	}
 
 	c.x11Events, c.x11Errors, err = c.x.Poll(ctx)
 	if err != nil {
 		return fmt.Errorf("(init) X poll: %w", err)
 	}
 	inputs := make(chan Input, 256)
-	c.inputMgr = inputManager{c.conf, c.x, nil, 0}
+	c.inputMgr = inputManager{
+		conf:  c.conf,
+		x:     c.x,
+		binds: c.conf.Keybinds,
+	}
 	c.inputs = inputs
 	go c.inputMgr.Run(inputs)
 
@@ -165,10 +205,18 @@ func Run(conf *cfg.Profile) error {
 
 	log.Info("Ready.")
 	go c.dbg.Run()
+	if conf.Limits.Memory > 0 || conf.Limits.Goroutines > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.dbg.Monitor(ctx)
+		}()
+	}
 	err = c.run()
 	if err != nil {
 		fmt.Println("Failed to run:", err)
 	}
+	c.dbg.printSummary()
 	return nil
 }
 
@@ -196,13 +244,17 @@ func ToggleBoateye(enable bool) {
 // ToggleResolution switches the given instance between the normal (play)
 // resolution and the given alternate resolution.
 func (c *Controller) ToggleResolution(resId int) {
-	if c.manager.ToggleResolution(resId) {
-		c.RunHook(HookAltRes, resId)
+	alt, changed := c.manager.ToggleResolution(resId)
+	if !changed {
+		return
+	}
+	if alt {
+		c.RunHook(cfg.HookAltRes, resId)
 		if (resId == BoateyeRes) {
 			ToggleBoateye(true)
 		}
 	} else {
-		c.RunHook(HookNormalRes, resId)
+		c.RunHook(cfg.HookNormalRes, resId)
 		if (boateyeEnabled) {
 			ToggleBoateye(false);
 		}
@@ -212,7 +264,26 @@ func (c *Controller) ToggleResolution(resId int) {
 // ResetInstance attempts to reset the given instance and returns whether or
 // not the reset was successful.
 func (c *Controller) ResetInstance() bool {
-	return c.manager.Reset()
+	if !c.manager.Reset() {
+		return false
+	}
+	c.resets.Add(1)
+	c.lastReset.Store(time.Now().UnixNano())
+	c.playResetSound()
+	if c.split != nil {
+		c.split.Send(splitReset)
+	}
+	return true
+}
+
+// SinceLastReset returns the time elapsed since the instance was last reset,
+// or false if it has not been reset during this session.
+func (c *Controller) SinceLastReset() (time.Duration, bool) {
+	last := c.lastReset.Load()
+	if last == 0 {
+		return 0, false
+	}
+	return time.Since(time.Unix(0, last)), true
 }
 
 // RunHook runs the hook of the given type if it exists.
@@ -225,22 +296,143 @@ func (c *Controller) RunHook(hook int, hookId int) {
 	if cmdStr == "" {
 		return
 	}
-	go func() {
-		bin, rawArgs, ok := strings.Cut(cmdStr, " ")
-		var args []string
-		if ok {
-			args = strings.Split(rawArgs, " ")
+	if c.syncHooks[hook] {
+		timeout := time.Duration(c.conf.Hooks.Timeout) * time.Millisecond
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err := makeCommand(ctx, cmdStr).Run()
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Error("RunHook (%d) timed out after %s", hook, timeout)
+		} else if err != nil {
+			log.Error("RunHook (%d) failed: %s", hook, err)
 		}
-		cmd := exec.Command(bin, args...)
-		err := cmd.Run()
+		return
+	}
+	go func() {
+		err := makeCommand(context.Background(), cmdStr).Run()
 		if err != nil {
 			log.Error("RunHook (%d) failed: %s", hook, err)
 		}
 	}()
 }
 
+// loadHooks reads the user's hooks from the configuration profile.
+func (c *Controller) loadHooks() {
+	c.hooks = map[int][]string{
+		cfg.HookReset:       {c.conf.Hooks.Reset},
+		cfg.HookAltRes:      c.conf.Hooks.AltRes,
+		cfg.HookNormalRes:   c.conf.Hooks.NormalRes,
+		cfg.HookFocusLost:   {c.conf.Hooks.FocusLost},
+		cfg.HookFocusGained: {c.conf.Hooks.FocusGained},
+	}
+	c.syncHooks = make(map[int]bool)
+	for _, name := range c.conf.Hooks.Sync {
+		c.syncHooks[cfg.HookNames[name]] = true
+	}
+}
+
+// playResetSound plays the configured reset sound, if any. If the previous
+// sound is still playing, no new sound is played.
+func (c *Controller) playResetSound() {
+	if c.conf.ResetSound == "" {
+		return
+	}
+	if !c.soundPlaying.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer c.soundPlaying.Store(false)
+		cmd := makeCommand(context.Background(), c.conf.ResetSound)
+		if err := cmd.Run(); err != nil {
+			log.Error("Reset sound failed: %s", err)
+		}
+	}()
+}
+
+// applyLayout resolves the keys in the user's keybinds according to the
+// current keyboard layout.
+func (c *Controller) applyLayout() error {
+	layout, err := c.x.GetLayoutKeycodes()
+	if err != nil {
+		return err
+	}
+	c.conf.Keybinds = c.conf.Keybinds.WithLayout(layout)
+	return nil
+}
+
+// makeCommand creates a command from the given string. The first word is used
+// as the program to run and any further words are passed as arguments.
+func makeCommand(ctx context.Context, cmdStr string) *exec.Cmd {
+	bin, rawArgs, ok := strings.Cut(cmdStr, " ")
+	var args []string
+	if ok {
+		args = strings.Split(rawArgs, " ")
+	}
+	return exec.CommandContext(ctx, bin, args...)
+}
+
+// checkResolutions warns about any configured resolutions which do not fit on
+// the screen with the given instance window, and clamps them to fit if the user
+// has enabled clamp_res.
+func (c *Controller) checkResolutions(wid xproto.Window) error {
+	w, h, err := c.x.GetScreenSize(wid)
+	if err != nil {
+		return err
+	}
+	check := func(name string, r *cfg.Rectangle) {
+		if r == nil {
+			return
+		}
+		clamped := r.Clamp(uint32(w), uint32(h))
+		if clamped == *r {
+			return
+		}
+		if !c.conf.ClampRes {
+			log.Warn("%s (%dx%d+%d,%d) does not fit on the screen (%dx%d)", name, r.W, r.H, r.X, r.Y, w, h)
+			return
+		}
+		log.Warn(
+			"%s (%dx%d+%d,%d) does not fit on the screen (%dx%d), using %dx%d+%d,%d",
+			name, r.W, r.H, r.X, r.Y, w, h, clamped.W, clamped.H, clamped.X, clamped.Y,
+		)
+		*r = clamped
+	}
+	check("play_res", c.conf.NormalRes)
+	check("startup_res", c.conf.StartRes)
+	for i := range c.conf.AltRes {
+		check(fmt.Sprintf("alt_res %d", i+1), &c.conf.AltRes[i])
+	}
+	return nil
+}
+
+// grabButtons grabs any mouse buttons used in the user's keybinds which
+// cannot be queried normally (buttons 6-9.)
+func (c *Controller) grabButtons() error {
+	grabbed := make(map[xproto.Button]bool)
+	for bind := range c.conf.Keybinds {
+		if bind.Button == nil || *bind.Button <= xproto.ButtonIndex5 {
+			continue
+		}
+		button := *bind.Button
+		if grabbed[button] {
+			continue
+		}
+		if err := c.x.GrabButton(button); err != nil {
+			return fmt.Errorf("grab button %d: %w", button, err)
+		}
+		grabbed[button] = true
+	}
+	return nil
+}
+
 // run runs the main loop for the controller.
 func (c *Controller) run() error {
+	// The most recent focus change, which is processed once the focus has
+	// settled for the configured debounce period.
+	var pendingFocus x11.Event
+	var focusSettled <-chan time.Time
+	debounce := time.Duration(c.conf.FocusDebounce) * time.Millisecond
+
 	for {
 		select {
 		case sig := <-c.signals:
@@ -257,13 +449,37 @@ func (c *Controller) run() error {
 			}
 			log.Error("X error: %s", err)
 		case evt := <-c.x11Events:
+			if _, ok := evt.(x11.MappingEvent); ok {
+				if err := c.applyLayout(); err != nil {
+					log.Error("Failed to update keyboard layout: %s", err)
+				} else {
+					c.inputMgr.SetBinds(c.conf.Keybinds)
+					log.Info("Keyboard layout changed, updated keybinds.")
+				}
+				continue
+			}
+			if _, ok := evt.(x11.FocusEvent); ok && debounce > 0 {
+				pendingFocus = evt
+				focusSettled = time.After(debounce)
+				continue
+			}
 			c.frontend.ProcessEvent(evt)
+		case <-focusSettled:
+			focusSettled = nil
+			c.frontend.ProcessEvent(pendingFocus)
 		case input := <-c.inputs:
 			c.frontend.Input(input)
 		}
 	}
 }
 
+// SetBinds replaces the keybinds which the inputManager checks for.
+func (i *inputManager) SetBinds(binds cfg.Keybinds) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.binds = binds
+}
+
 func (i *inputManager) Run(inputs chan<- Input) {
 	for {
 		// Sleep for this polling iteration and query the input devices' state.
@@ -296,8 +512,11 @@ func (i *inputManager) Run(inputs chan<- Input) {
 		}
 		
 		// PERF: This is kind of bad and can probably be optimized
+		i.mu.Lock()
+		binds := i.binds
+		i.mu.Unlock()
 		var pressed []cfg.Bind
-		for bind := range i.conf.Keybinds {
+		for bind := range binds {
 			var mask [32]byte
 			if bind.Key != nil {
 				key := *bind.Key
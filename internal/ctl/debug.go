@@ -2,11 +2,13 @@ package ctl
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/tesselslate/resetti/internal/log"
 )
@@ -52,6 +54,41 @@ func (d *debugLogger) Run() {
 	}
 }
 
+// Monitor periodically checks resetti's memory usage and goroutine count and
+// logs a warning when either exceeds the configured limits.
+func (d *debugLogger) Monitor(ctx context.Context) {
+	limits := d.host.conf.Limits
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	var memAlert, goroutineAlert bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mem := runtime.MemStats{}
+			runtime.ReadMemStats(&mem)
+			memMb := mem.Sys / 1e6
+			if limits.Memory > 0 {
+				over := memMb > uint64(limits.Memory)
+				if over && !memAlert {
+					log.Warn("Memory usage (%d MB) exceeds limit (%d MB)", memMb, limits.Memory)
+				}
+				memAlert = over
+			}
+			goroutines := runtime.NumGoroutine()
+			if limits.Goroutines > 0 {
+				over := goroutines > limits.Goroutines
+				if over && !goroutineAlert {
+					log.Warn("Goroutine count (%d) exceeds limit (%d)", goroutines, limits.Goroutines)
+				}
+				goroutineAlert = over
+			}
+		}
+	}
+}
+
 func (d *debugLogger) printAll() {
 	d.printFrontend()
 	d.printGc()
@@ -61,6 +98,11 @@ func (d *debugLogger) printAll() {
 func (d *debugLogger) printFrontend() {
 	s := &strings.Builder{}
 	s.WriteString("\nFrontend: \n")
+	if since, ok := d.host.SinceLastReset(); ok {
+		fmt.Fprintf(s, "Time since last reset: %s", since.Round(time.Millisecond))
+	} else {
+		s.WriteString("Time since last reset: never reset")
+	}
 	log.Debug(s.String())
 }
 
@@ -87,3 +129,15 @@ func (d *debugLogger) printInput() {
 	fmt.Fprintf(s, "Last fail window: %d", d.host.inputMgr.lastFailWindow)
 	log.Debug(s.String())
 }
+
+// printSummary prints statistics about the session which is ending.
+func (d *debugLogger) printSummary() {
+	duration := time.Since(d.host.start)
+	s := &strings.Builder{}
+	s.WriteString("\nSession summary: \n")
+	fmt.Fprintf(s, "Duration: %s\n", duration.Round(time.Second))
+	resets := d.host.resets.Load()
+	fmt.Fprintf(s, "Resets: %d\n", resets)
+	fmt.Fprintf(s, "Resets per hour: %.1f", float64(resets)/duration.Hours())
+	log.Info(s.String())
+}
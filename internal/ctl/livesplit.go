@@ -0,0 +1,81 @@
+package ctl
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/tesselslate/resetti/internal/log"
+)
+
+// LiveSplit server commands
+const (
+	splitReset = "reset"
+)
+
+// liveSplit sends timer commands to a LiveSplit server in the background. Any
+// connection errors are logged and never stop resetti.
+type liveSplit struct {
+	addr string
+	conn net.Conn
+	cmds chan string
+}
+
+// newLiveSplit creates a new liveSplit for the server at the given address.
+func newLiveSplit(addr string) *liveSplit {
+	return &liveSplit{
+		addr: addr,
+		cmds: make(chan string, 16),
+	}
+}
+
+// Run sends queued commands to the server until the context is cancelled.
+func (l *liveSplit) Run(ctx context.Context) {
+	defer func() {
+		if l.conn != nil {
+			_ = l.conn.Close()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cmd := <-l.cmds:
+			if err := l.send(cmd); err != nil {
+				log.Error("LiveSplit: send %q failed: %s", cmd, err)
+			}
+		}
+	}
+}
+
+// Send queues a command to be sent to the server. If the queue is full, the
+// command is dropped.
+func (l *liveSplit) Send(cmd string) {
+	select {
+	case l.cmds <- cmd:
+	default:
+		log.Warn("LiveSplit: dropped command %q", cmd)
+	}
+}
+
+// send sends a single command to the server, connecting (or reconnecting) if
+// needed.
+func (l *liveSplit) send(cmd string) error {
+	if l.conn == nil {
+		conn, err := net.DialTimeout("tcp", l.addr, time.Second)
+		if err != nil {
+			return err
+		}
+		l.conn = conn
+	}
+	if err := l.conn.SetWriteDeadline(time.Now().Add(time.Second)); err != nil {
+		return err
+	}
+	if _, err := l.conn.Write([]byte(cmd + "\r\n")); err != nil {
+		// Drop the connection so that the next command reconnects.
+		_ = l.conn.Close()
+		l.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package ctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tesselslate/resetti/internal/log"
+)
+
+// serveMetrics serves session metrics in the Prometheus text exposition
+// format at the given address until the context is cancelled.
+func (c *Controller) serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		s := &strings.Builder{}
+		s.WriteString("# HELP resetti_resets_total Number of resets this session.\n")
+		s.WriteString("# TYPE resetti_resets_total counter\n")
+		fmt.Fprintf(s, "resetti_resets_total %d\n", c.resets.Load())
+		s.WriteString("# HELP resetti_session_seconds Time since resetti started.\n")
+		s.WriteString("# TYPE resetti_session_seconds gauge\n")
+		fmt.Fprintf(s, "resetti_session_seconds %.0f\n", time.Since(c.start).Seconds())
+		if since, ok := c.SinceLastReset(); ok {
+			s.WriteString("# HELP resetti_last_reset_seconds Time since the instance was last reset.\n")
+			s.WriteString("# TYPE resetti_last_reset_seconds gauge\n")
+			fmt.Fprintf(s, "resetti_last_reset_seconds %.3f\n", since.Seconds())
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if _, err := w.Write([]byte(s.String())); err != nil {
+			log.Error("Failed to write metrics: %s", err)
+		}
+	})
+	server := http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	log.Info("Serving metrics on %s", addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Error("Metrics server failed: %s", err)
+	}
+}
@@ -3,6 +3,7 @@ package ctl
 import (
 	"github.com/jezek/xgb/xproto"
 	"github.com/tesselslate/resetti/internal/cfg"
+	"github.com/tesselslate/resetti/internal/log"
 	"github.com/tesselslate/resetti/internal/mc"
 	"github.com/tesselslate/resetti/internal/x11"
 )
@@ -12,9 +13,13 @@ import (
 type Single struct {
 	host *Controller
 	conf *cfg.Profile
-	x    *x11.Client
+	x    x11.Conn
 
 	instance mc.InstanceInfo
+
+	// Whether or not measuring mode is enabled. Ingame resets are ignored
+	// while measuring.
+	measuring bool
 }
 
 // Setup implements Frontend.
@@ -56,8 +61,19 @@ func (m *Single) Input(input Input) {
 			if m.x.GetActiveWindow() != m.instance.Wid {
 				continue
 			}
+			if m.measuring {
+				log.Info("Measuring mode is enabled, ignoring reset.")
+				continue
+			}
 			if m.host.ResetInstance() {
-				m.host.RunHook(HookReset, 0)
+				m.host.RunHook(cfg.HookReset, 0)
+			}
+		case cfg.ActionIngameMeasure:
+			m.measuring = !m.measuring
+			if m.measuring {
+				log.Info("Measuring mode enabled. Resets are disabled.")
+			} else {
+				log.Info("Measuring mode disabled.")
 			}
 		}
 	}
@@ -68,9 +84,9 @@ func (m *Single) ProcessEvent(evt x11.Event) {
 	switch evt := evt.(type) {
 	case x11.FocusEvent:
 		if m.instance.Wid == xproto.Window(evt) {
-			m.host.RunHook(HookFocusGained, 0)
+			m.host.RunHook(cfg.HookFocusGained, 0)
 		} else {
-			m.host.RunHook(HookFocusLost, 0)
+			m.host.RunHook(cfg.HookFocusLost, 0)
 		}
 	}
 }
@@ -0,0 +1,214 @@
+package ctl
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+	"github.com/tesselslate/resetti/internal/cfg"
+	"github.com/tesselslate/resetti/internal/log"
+	"github.com/tesselslate/resetti/internal/mc"
+	"github.com/tesselslate/resetti/internal/x11"
+	"github.com/tesselslate/resetti/internal/x11/x11test"
+)
+
+const testWid = xproto.Window(100)
+
+// TestMain sets up a logger for the package's code to log to. The logger is
+// not closed, since closing it removes the log configuration which tests in
+// other packages may be using at the same time.
+func TestMain(m *testing.M) {
+	_ = log.DefaultLogger(log.ERROR, "", true)
+	os.Exit(m.Run())
+}
+
+// newTestSingle creates a Controller and Single frontend for a single
+// instance window on a fake X server, with the given keybinds (bind -> action
+// list.) Any requests made during setup are discarded.
+func newTestSingle(t *testing.T, conf *cfg.Profile, binds map[string]any) (*Controller, *Single, *x11test.Conn) {
+	t.Helper()
+	if err := conf.Keybinds.UnmarshalTOML(binds); err != nil {
+		t.Fatalf("parse keybinds: %s", err)
+	}
+
+	x := x11test.NewConn()
+	x.AddWindow(testWid, x11test.Window{
+		Class: "Minecraft* 1.16.1",
+		Title: "Minecraft* 1.16.1",
+		W:     1920,
+		H:     1080,
+	})
+	info := mc.InstanceInfo{Wid: testWid, Version: 16, ResetKey: x11.KeyF6}
+
+	c := &Controller{conf: conf, x: x}
+	c.loadHooks()
+	manager, err := mc.NewManager(info, conf, x)
+	if err != nil {
+		t.Fatalf("create manager: %s", err)
+	}
+	c.manager = manager
+	s := &Single{}
+	err = s.Setup(frontendDependencies{
+		conf:     conf,
+		x:        x,
+		instance: info,
+		host:     c,
+	})
+	if err != nil {
+		t.Fatalf("setup frontend: %s", err)
+	}
+	c.frontend = s
+	x.Reset()
+	return c, s, x
+}
+
+// press returns an input for the bind with the given name.
+func press(t *testing.T, conf *cfg.Profile, name string) Input {
+	t.Helper()
+	for bind := range conf.Keybinds {
+		if bind.String() == name {
+			return Input{Bind: bind}
+		}
+	}
+	t.Fatalf("no bind %q", name)
+	return Input{}
+}
+
+// keys returns the key requests in the given calls.
+func keys(calls []x11test.Call) []x11test.Call {
+	var out []x11test.Call
+	for _, call := range calls {
+		switch call.Op {
+		case x11test.OpKeyDown, x11test.OpKeyPress, x11test.OpKeyUp:
+			out = append(out, x11test.Call{Op: call.Op, Win: call.Win, Key: call.Key})
+		}
+	}
+	return out
+}
+
+var resetCalls = []x11test.Call{
+	{Op: x11test.OpKeyUp, Win: testWid, Key: x11.KeyShift},
+	{Op: x11test.OpKeyPress, Win: testWid, Key: x11.KeyF3},
+	{Op: x11test.OpKeyPress, Win: testWid, Key: x11.KeyF6},
+}
+
+func TestSingleReset(t *testing.T) {
+	conf := &cfg.Profile{}
+	c, s, x := newTestSingle(t, conf, map[string]any{
+		"U": []any{"ingame_reset"},
+	})
+
+	// Held inputs are ignored.
+	input := press(t, conf, "U")
+	input.Held = true
+	s.Input(input)
+	if got := keys(x.Calls()); got != nil {
+		t.Fatalf("held input: got calls %+v, want none", got)
+	}
+
+	s.Input(press(t, conf, "U"))
+	if got := keys(x.Calls()); !reflect.DeepEqual(got, resetCalls) {
+		t.Errorf("got calls %+v, want %+v", got, resetCalls)
+	}
+	if resets := c.resets.Load(); resets != 1 {
+		t.Errorf("got %d resets, want 1", resets)
+	}
+	if _, ok := c.SinceLastReset(); !ok {
+		t.Error("last reset time was not recorded")
+	}
+
+	// Resets are ignored when the instance is not focused.
+	x.Reset()
+	if err := x.FocusWindow(testWid + 1); err != nil {
+		t.Fatal(err)
+	}
+	s.Input(press(t, conf, "U"))
+	if got := keys(x.Calls()); got != nil {
+		t.Errorf("unfocused: got calls %+v, want none", got)
+	}
+}
+
+func TestSingleMeasuring(t *testing.T) {
+	conf := &cfg.Profile{}
+	_, s, x := newTestSingle(t, conf, map[string]any{
+		"U": []any{"ingame_reset"},
+		"M": []any{"ingame_toggle_measure"},
+	})
+
+	s.Input(press(t, conf, "M"))
+	s.Input(press(t, conf, "U"))
+	if got := keys(x.Calls()); got != nil {
+		t.Fatalf("measuring: got calls %+v, want none", got)
+	}
+
+	s.Input(press(t, conf, "M"))
+	s.Input(press(t, conf, "U"))
+	if got := keys(x.Calls()); !reflect.DeepEqual(got, resetCalls) {
+		t.Errorf("after measuring: got calls %+v, want %+v", got, resetCalls)
+	}
+}
+
+func TestSingleSyncHook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reset")
+	conf := &cfg.Profile{
+		Hooks: cfg.Hooks{
+			Reset:   "touch " + path,
+			Sync:    []string{"reset"},
+			Timeout: 5000,
+		},
+	}
+	_, s, _ := newTestSingle(t, conf, map[string]any{
+		"U": []any{"ingame_reset"},
+	})
+
+	// A synchronous hook has finished by the time the input is processed.
+	s.Input(press(t, conf, "U"))
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("reset hook did not run: %s", err)
+	}
+}
+
+// recordFrontend is a Frontend which records the events it receives.
+type recordFrontend struct {
+	events []x11.Event
+}
+
+func (r *recordFrontend) Input(Input)                      {}
+func (r *recordFrontend) Setup(frontendDependencies) error { return nil }
+func (r *recordFrontend) ProcessEvent(evt x11.Event)       { r.events = append(r.events, evt) }
+
+func TestControllerFocusDebounce(t *testing.T) {
+	events := make(chan x11.Event, 8)
+	signals := make(chan os.Signal, 1)
+	frontend := &recordFrontend{}
+	c := &Controller{
+		conf:      &cfg.Profile{FocusDebounce: 50},
+		frontend:  frontend,
+		x11Events: events,
+		x11Errors: make(chan error),
+		signals:   signals,
+	}
+	done := make(chan error)
+	go func() {
+		done <- c.run()
+	}()
+
+	// Only the last of several quick focus changes is processed.
+	events <- x11.FocusEvent(1)
+	events <- x11.FocusEvent(2)
+	events <- x11.FocusEvent(testWid)
+	time.Sleep(200 * time.Millisecond)
+	signals <- syscall.SIGTERM
+	if err := <-done; err != nil {
+		t.Fatalf("run: %s", err)
+	}
+
+	want := []x11.Event{x11.FocusEvent(testWid)}
+	if !reflect.DeepEqual(frontend.events, want) {
+		t.Errorf("got events %v, want %v", frontend.events, want)
+	}
+}
@@ -31,11 +31,17 @@ func (c *LogConf) UpdateLevel(level LogLevel) error {
 }
 
 // Write is used to write a configuration to `/tmp/resetti.json`.
+// The configuration is written to a temporary file which then replaces the
+// old one, so that other processes never read a partially written file.
 func (c *LogConf) Write() error {
-	logFile, err := os.OpenFile("/tmp/resetti.json", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	logFile, err := os.CreateTemp("/tmp", "resetti.json.*")
 	if err != nil {
 		return fmt.Errorf("Failed to open config: %s", err)
 	}
+	defer func() {
+		_ = logFile.Close()
+		_ = os.Remove(logFile.Name())
+	}()
 	byteConf, err := json.MarshalIndent(c, "", " ")
 	if err != nil {
 		return fmt.Errorf("Failed to jsonify config: %s", err)
@@ -44,5 +50,11 @@ func (c *LogConf) Write() error {
 	if err != nil {
 		return fmt.Errorf("Failed to write config: %s", err)
 	}
+	if err = logFile.Chmod(0644); err != nil {
+		return fmt.Errorf("Failed to write config: %s", err)
+	}
+	if err = os.Rename(logFile.Name(), "/tmp/resetti.json"); err != nil {
+		return fmt.Errorf("Failed to write config: %s", err)
+	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +16,10 @@ import (
 
 // TODO: Pre 1.14 support
 
+// How long to wait for the window manager to take an instance out of
+// fullscreen.
+const fullscreenTimeout = 500 * time.Millisecond
+
 // An instance contains all of the relevant information for an instance, such
 // as its game directory and current state.
 type instance struct {
@@ -33,11 +38,11 @@ type Manager struct {
 	instance instance // Minecraft instance being managed
 
 	conf *cfg.Profile
-	x    *x11.Client
+	x    x11.Conn
 }
 
 // NewManager attempts to create a new Manager for the given instances.
-func NewManager(info InstanceInfo, conf *cfg.Profile, x *x11.Client) (*Manager, error) {
+func NewManager(info InstanceInfo, conf *cfg.Profile, x x11.Conn) (*Manager, error) {
 	// Create instance.
 	instance := instance{info, false}
 
@@ -47,7 +52,29 @@ func NewManager(info InstanceInfo, conf *cfg.Profile, x *x11.Client) (*Manager,
 		conf,
 		x,
 	}
-	x.Click(info.Wid)
+	if conf.StartRes != nil && m.setResolution(conf.StartRes) {
+		r := conf.StartRes
+		log.Info("Set instance resolution to %dx%d+%d,%d", r.W, r.H, r.X, r.Y)
+	} else if conf.NormalRes != nil {
+		w, h, err := x.GetWindowSize(info.Wid)
+		if err != nil {
+			log.Warn("Failed to get instance window size: %s", err)
+		} else if uint32(w) != conf.NormalRes.W || uint32(h) != conf.NormalRes.H {
+			log.Warn(
+				"Instance window is %dx%d but play_res is %dx%d. Check your configuration if this is unexpected.",
+				w, h, conf.NormalRes.W, conf.NormalRes.H,
+			)
+		} else {
+			log.Debug("Instance window is %dx%d", w, h)
+		}
+	}
+	if conf.SafeInput {
+		log.Info("Safe input mode enabled.")
+	}
+	log.Info("Synthetic inputs enabled: %s", strings.Join(syntheticInputs(conf), ", "))
+	if !conf.SafeInput {
+		x.Click(info.Wid)
+	}
 
 	return &m, nil
 }
@@ -72,8 +99,11 @@ func (m *Manager) Run(ctx context.Context) {
 }
 
 // Focus attempts to focus the window of the given instance. Any errors will
-// be logged.
+// be logged. Nothing is done in safe input mode.
 func (m *Manager) Focus() {
+	if m.conf.SafeInput {
+		return
+	}
 	if err := m.x.FocusWindow(m.instance.info.Wid); err != nil {
 		log.Error("Focus failed: %s", err)
 	}
@@ -81,16 +111,21 @@ func (m *Manager) Focus() {
 
 // ToggleResolution switches the given instance between the normal (play)
 // resolution and the given alternate resolution. It returns whether or not
-// the instance is now using the alternate resolution.
-func (m *Manager) ToggleResolution(resId int) bool {
+// the instance is now using the alternate resolution, and whether or not the
+// resolution was actually changed.
+func (m *Manager) ToggleResolution(resId int) (alt bool, changed bool) {
+	var ok bool
 	if m.instance.altRes {
-		m.setResolution(m.conf.NormalRes)
+		ok = m.setResolution(m.conf.NormalRes)
 	} else {
-		m.setResolution(&m.conf.AltRes[resId])
+		ok = m.setResolution(&m.conf.AltRes[resId])
+	}
+	if !ok {
+		return m.instance.altRes, false
 	}
 	m.instance.altRes = !m.instance.altRes
 	m.Focus()
-	return m.instance.altRes
+	return m.instance.altRes, true
 }
 
 // Reset attempts to reset the given instance. The return value will indicate
@@ -102,16 +137,28 @@ func (m *Manager) Reset() bool {
 	defer m.mu.Unlock()
 
 	// Ghost pie fix.
-	m.sendKeyUp(x11.KeyShift)
-	m.sendKeyPress(x11.KeyF3)
-	if m.instance.altRes {
-		m.setResolution(m.conf.NormalRes)
+	if !m.conf.SafeInput {
+		m.sendKeyUp(x11.KeyShift)
+		m.sendKeyPress(x11.KeyF3)
+	}
+	if m.instance.altRes && m.setResolution(m.conf.NormalRes) {
 		m.instance.altRes = false
 	}
-	m.sendKeyPress(m.instance.info.ResetKey)
+	if m.conf.ResetHold > 0 {
+		m.sendKeyDown(m.instance.info.ResetKey)
+		time.Sleep(time.Duration(m.conf.ResetHold) * time.Millisecond)
+		m.sendKeyUp(m.instance.info.ResetKey)
+	} else {
+		m.sendKeyPress(m.instance.info.ResetKey)
+	}
 	return true
 }
 
+// sendKeyDown sends a key down event to the given instance.
+func (m *Manager) sendKeyDown(key xproto.Keycode) {
+	m.x.SendKeyDown(key, m.instance.info.Wid)
+}
+
 // sendKeyPress sends a key down and key up event to the given instance.
 func (m *Manager) sendKeyPress(key xproto.Keycode) {
 	m.x.SendKeyPress(key, m.instance.info.Wid)
@@ -122,13 +169,76 @@ func (m *Manager) sendKeyUp(key xproto.Keycode) {
 	m.x.SendKeyUp(key, m.instance.info.Wid)
 }
 
-// setResolution sets the window geometry of an instance.
-func (m *Manager) setResolution(rect *cfg.Rectangle) {
+// setResolution sets the window geometry of an instance. It returns whether
+// or not the geometry was changed.
+func (m *Manager) setResolution(rect *cfg.Rectangle) bool {
 	if rect == nil {
-		return
+		return false
+	}
+	wid := m.instance.info.Wid
+	fullscreen, err := m.x.IsFullscreen(wid)
+	if err != nil {
+		log.Error("Failed to check fullscreen state: %s", err)
+	} else if fullscreen {
+		if !m.conf.ExitFullscreen {
+			log.Warn("Instance is fullscreen, not changing resolution.")
+			return false
+		}
+		if m.conf.SafeInput {
+			log.Warn("Instance is fullscreen and safe input mode is enabled, not changing resolution.")
+			return false
+		}
+		if err := m.x.SetFullscreen(wid, false); err != nil {
+			log.Error("Failed to exit fullscreen: %s", err)
+			return false
+		}
+		// The window manager restores the window's old geometry when it
+		// leaves fullscreen, which would undo the move if it happened first.
+		if !m.waitFullscreenExit() {
+			log.Warn("Instance did not leave fullscreen, not changing resolution.")
+			return false
+		}
 	}
 	m.x.MoveWindow(
-		m.instance.info.Wid,
+		wid,
 		rect.X, rect.Y, rect.W, rect.H,
 	)
+	return true
+}
+
+// waitFullscreenExit waits for the window manager to take the instance out of
+// fullscreen. It returns whether or not the instance left fullscreen in time.
+func (m *Manager) waitFullscreenExit() bool {
+	deadline := time.Now().Add(fullscreenTimeout)
+	for {
+		fullscreen, err := m.x.IsFullscreen(m.instance.info.Wid)
+		if err != nil {
+			log.Error("Failed to check fullscreen state: %s", err)
+			return false
+		}
+		if !fullscreen {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// syntheticInputs returns a description of each kind of synthetic input which
+// is sent to the instance with the given configuration.
+func syntheticInputs(conf *cfg.Profile) []string {
+	inputs := []string{"reset key"}
+	if conf.ResetHold > 0 {
+		inputs[0] = fmt.Sprintf("reset key (held for %dms)", conf.ResetHold)
+	}
+	if conf.SafeInput {
+		return inputs
+	}
+	inputs = append(inputs, "ghost pie fix (Shift, F3)", "window focus", "startup click")
+	if conf.ExitFullscreen {
+		inputs = append(inputs, "exiting fullscreen")
+	}
+	return inputs
 }
@@ -0,0 +1,218 @@
+package mc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+	"github.com/tesselslate/resetti/internal/cfg"
+	"github.com/tesselslate/resetti/internal/x11"
+	"github.com/tesselslate/resetti/internal/x11/x11test"
+)
+
+const testWid = xproto.Window(100)
+
+// newTestManager creates a Manager for a single instance window on a fake X
+// server. Any requests made while creating the Manager are discarded.
+func newTestManager(t *testing.T, conf *cfg.Profile) (*Manager, *x11test.Conn) {
+	t.Helper()
+	return newTestManagerWindow(t, conf, x11test.Window{})
+}
+
+// newTestManagerWindow is like newTestManager, but the instance window starts
+// with the given state.
+func newTestManagerWindow(t *testing.T, conf *cfg.Profile, window x11test.Window) (*Manager, *x11test.Conn) {
+	t.Helper()
+	x := x11test.NewConn()
+	window.Class = "Minecraft* 1.16.1"
+	window.Title = "Minecraft* 1.16.1"
+	window.W, window.H = 1920, 1080
+	x.AddWindow(testWid, window)
+	info := InstanceInfo{Wid: testWid, Version: 16, ResetKey: x11.KeyF6}
+	m, err := NewManager(info, conf, x)
+	if err != nil {
+		t.Fatalf("create manager: %s", err)
+	}
+	x.Reset()
+	return m, x
+}
+
+// ops returns the given calls without their timestamps.
+func ops(calls []x11test.Call) []x11test.Call {
+	var out []x11test.Call
+	for _, call := range calls {
+		call.Time = time.Time{}
+		out = append(out, call)
+	}
+	return out
+}
+
+func TestManagerReset(t *testing.T) {
+	tests := []struct {
+		name string
+		safe bool
+		want []x11test.Call
+	}{
+		{"Normal", false, []x11test.Call{
+			{Op: x11test.OpKeyUp, Win: testWid, Key: x11.KeyShift},
+			{Op: x11test.OpKeyPress, Win: testWid, Key: x11.KeyF3},
+			{Op: x11test.OpKeyPress, Win: testWid, Key: x11.KeyF6},
+		}},
+		{"SafeInput", true, []x11test.Call{
+			{Op: x11test.OpKeyPress, Win: testWid, Key: x11.KeyF6},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, x := newTestManager(t, &cfg.Profile{SafeInput: tt.safe})
+			if !m.Reset() {
+				t.Fatal("reset failed")
+			}
+			if got := ops(x.Calls()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got calls %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerToggleResolution(t *testing.T) {
+	normal := cfg.Rectangle{X: 0, Y: 0, W: 1920, H: 1080}
+	alt := cfg.Rectangle{X: 810, Y: 0, W: 300, H: 1080}
+	conf := &cfg.Profile{NormalRes: &normal, AltRes: cfg.AltRes{alt}}
+	m, x := newTestManager(t, conf)
+
+	if alt, changed := m.ToggleResolution(0); !alt || !changed {
+		t.Fatalf("first toggle: got alt %t changed %t, want both true", alt, changed)
+	}
+	want := []x11test.Call{
+		{Op: x11test.OpMove, Win: testWid, X: alt.X, Y: alt.Y, W: alt.W, H: alt.H},
+		{Op: x11test.OpFocus, Win: testWid},
+	}
+	if got := ops(x.Calls()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got calls %+v, want %+v", got, want)
+	}
+
+	x.Reset()
+	if alt, changed := m.ToggleResolution(0); alt || !changed {
+		t.Fatalf("second toggle: got alt %t changed %t, want false and true", alt, changed)
+	}
+	want = []x11test.Call{
+		{Op: x11test.OpMove, Win: testWid, X: normal.X, Y: normal.Y, W: normal.W, H: normal.H},
+		{Op: x11test.OpFocus, Win: testWid},
+	}
+	if got := ops(x.Calls()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got calls %+v, want %+v", got, want)
+	}
+}
+
+func TestManagerResetRestoresResolution(t *testing.T) {
+	normal := cfg.Rectangle{X: 0, Y: 0, W: 1920, H: 1080}
+	alt := cfg.Rectangle{X: 810, Y: 0, W: 300, H: 1080}
+	conf := &cfg.Profile{NormalRes: &normal, AltRes: cfg.AltRes{alt}, SafeInput: true}
+	m, x := newTestManager(t, conf)
+
+	m.ToggleResolution(0)
+	x.Reset()
+	m.Reset()
+	want := []x11test.Call{
+		{Op: x11test.OpMove, Win: testWid, X: normal.X, Y: normal.Y, W: normal.W, H: normal.H},
+		{Op: x11test.OpKeyPress, Win: testWid, Key: x11.KeyF6},
+	}
+	if got := ops(x.Calls()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got calls %+v, want %+v", got, want)
+	}
+}
+
+func TestManagerToggleResolutionFullscreen(t *testing.T) {
+	normal := cfg.Rectangle{X: 0, Y: 0, W: 1920, H: 1080}
+	alt := cfg.Rectangle{X: 810, Y: 0, W: 300, H: 1080}
+	tests := []struct {
+		name    string
+		exit    bool
+		safe    bool
+		stuck   bool
+		changed bool
+		want    []x11test.Call
+	}{
+		{"Skip", false, false, false, false, nil},
+		{"SafeInput", true, true, false, false, nil},
+		{"Stuck", true, false, true, false, []x11test.Call{
+			{Op: x11test.OpFullscreen, Win: testWid, Full: false},
+		}},
+		{"Exit", true, false, false, true, []x11test.Call{
+			{Op: x11test.OpFullscreen, Win: testWid, Full: false},
+			{Op: x11test.OpMove, Win: testWid, X: alt.X, Y: alt.Y, W: alt.W, H: alt.H},
+			{Op: x11test.OpFocus, Win: testWid},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &cfg.Profile{
+				NormalRes:      &normal,
+				AltRes:         cfg.AltRes{alt},
+				ExitFullscreen: tt.exit,
+				SafeInput:      tt.safe,
+			}
+			m, x := newTestManagerWindow(t, conf, x11test.Window{
+				Fullscreen: true,
+				Stuck:      tt.stuck,
+			})
+
+			_, changed := m.ToggleResolution(0)
+			if changed != tt.changed {
+				t.Errorf("got changed %t, want %t", changed, tt.changed)
+			}
+			if got := ops(x.Calls()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got calls %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerResetHold(t *testing.T) {
+	const hold = 50
+	m, x := newTestManager(t, &cfg.Profile{SafeInput: true, ResetHold: hold})
+	if !m.Reset() {
+		t.Fatal("reset failed")
+	}
+	calls := x.Calls()
+	want := []x11test.Call{
+		{Op: x11test.OpKeyDown, Win: testWid, Key: x11.KeyF6},
+		{Op: x11test.OpKeyUp, Win: testWid, Key: x11.KeyF6},
+	}
+	if got := ops(calls); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got calls %+v, want %+v", got, want)
+	}
+	if held := calls[1].Time.Sub(calls[0].Time); held < hold*time.Millisecond {
+		t.Errorf("reset key held for %s, want at least %dms", held, hold)
+	}
+}
+
+func TestSyntheticInputs(t *testing.T) {
+	tests := []struct {
+		name string
+		conf cfg.Profile
+		want []string
+	}{
+		{"Default", cfg.Profile{}, []string{
+			"reset key", "ghost pie fix (Shift, F3)", "window focus", "startup click",
+		}},
+		{"ExitFullscreen", cfg.Profile{ExitFullscreen: true, ResetHold: 30}, []string{
+			"reset key (held for 30ms)", "ghost pie fix (Shift, F3)", "window focus", "startup click", "exiting fullscreen",
+		}},
+		{"SafeInput", cfg.Profile{SafeInput: true, ExitFullscreen: true}, []string{
+			"reset key",
+		}},
+		{"SafeInputHold", cfg.Profile{SafeInput: true, ResetHold: 30}, []string{
+			"reset key (held for 30ms)",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syntheticInputs(&tt.conf); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/jezek/xgb/xproto"
+	"github.com/tesselslate/resetti/internal/log"
 	"github.com/tesselslate/resetti/internal/x11"
 )
 
@@ -35,10 +36,14 @@ type InstanceInfo struct {
 
 // FindInstance returns the running Minecraft instance,
 // or an error if it doesn't find any.
-func FindInstance(x *x11.Client) (InstanceInfo, error) {
+func FindInstance(x x11.Conn) (InstanceInfo, error) {
 	windows := x.GetWindowList()
 
-	// Check every window to see if it is a Minecraft instance.
+	// Check every window to see if it is a Minecraft instance. Instances which
+	// cannot be used are skipped, so that a stray window (e.g. a vanilla
+	// instance) does not prevent resetti from starting.
+	var instances []InstanceInfo
+	var lastErr error
 	for _, win := range windows {
 		// Skip this window if it is not a Minecraft instance.
 		if !isMinecraftWindow(x, win) {
@@ -49,24 +54,52 @@ func FindInstance(x *x11.Client) (InstanceInfo, error) {
 		info, was_instance, err := getInstanceInfo(x, win)
 		if was_instance {
 			if err != nil {
-				return InstanceInfo{}, fmt.Errorf("unusable instance: %w", err)
+				log.Warn("Skipping unusable instance (window %d): %s", win, err)
+				lastErr = err
+				continue
 			}
-			return info, nil
+			instances = append(instances, info)
 		}
 	}
-	return InstanceInfo{}, fmt.Errorf("no instance found")
+	if len(instances) == 0 {
+		if lastErr != nil {
+			return InstanceInfo{}, fmt.Errorf("no usable instance found: %w", lastErr)
+		}
+		return InstanceInfo{}, fmt.Errorf("no instance found")
+	}
+
+	// Make sure the same instance was not found on several windows (e.g. a
+	// leftover window from a previous launch.)
+	wids := make(map[string][]xproto.Window)
+	for _, info := range instances {
+		wids[info.Dir] = append(wids[info.Dir], info.Wid)
+	}
+	for dir, list := range wids {
+		if len(list) > 1 {
+			return InstanceInfo{}, fmt.Errorf("instance (%s) found on multiple windows (wids %v), close the extra windows", dir, list)
+		}
+	}
+	if len(instances) > 1 {
+		log.Warn(
+			"Found %d instances, using %s (window %d) since it was found first in the window tree. Close the other instances to pick a different one.",
+			len(instances), instances[0].Dir, instances[0].Wid,
+		)
+	}
+	return instances[0], nil
 }
 
 // getInstanceInfo attempts to gather information about the given Minecraft
 // instance.
-func getInstanceInfo(x *x11.Client, win xproto.Window) (InstanceInfo, bool, error) {
+func getInstanceInfo(x x11.Conn, win xproto.Window) (InstanceInfo, bool, error) {
 	// Get process ID.
 	pid, err := x.GetWindowPid(win)
 	if err != nil {
 		return InstanceInfo{}, false, err
 	}
 
-	// Get instance directory.
+	// Get instance directory. This also skips windows whose process has
+	// exited or is a zombie (e.g. a leftover window from a crashed instance),
+	// since their working directory cannot be resolved.
 	rawPwd, err := filepath.EvalSymlinks(fmt.Sprintf("/proc/%d/cwd", pid))
 	if err != nil {
 		return InstanceInfo{}, false, err
@@ -96,44 +129,104 @@ func getInstanceInfo(x *x11.Client, win xproto.Window) (InstanceInfo, bool, erro
 		return InstanceInfo{}, true, fmt.Errorf("has modern wp: %w", err)
 	}
 
-	// Get the Atum and WorldPreview keys from the user's options.
-	options, err := os.ReadFile(pwd + "/options.txt")
+	// Get the Atum reset key from the user's options.
+	resetKey, err := getResetKey(pwd)
 	if err != nil {
-		return InstanceInfo{}, true, fmt.Errorf("couldn't open instance options.txt: %w", err)
+		return InstanceInfo{}, true, err
 	}
-	resetKey := x11.KeyF6
-	for _, line := range strings.Split(string(options), "\n") {
+
+	return InstanceInfo{
+		pid,
+		win,
+		pwd,
+		version,
+		modernWp,
+		resetKey,
+	}, true, nil
+}
+
+// getResetKey returns the Atum reset key for the instance in the given
+// directory. StandardSettings takes priority over options.txt, since it
+// overwrites options.txt on reset, so options.txt is only read if
+// StandardSettings does not set the key. If neither does, Atum's default (F6)
+// is returned.
+func getResetKey(dir string) (xproto.Keycode, error) {
+	standard, err := readStandardOptions(dir)
+	if err != nil {
+		return 0, fmt.Errorf("read standardoptions.txt: %w", err)
+	}
+	if standard != "" {
+		key, found, err := parseResetKey(standard)
+		if err != nil {
+			return 0, fmt.Errorf("standardoptions.txt: %w", err)
+		}
+		if found {
+			return key, nil
+		}
+	}
+
+	options, err := os.ReadFile(dir + "/options.txt")
+	if err != nil {
+		return 0, fmt.Errorf("couldn't open instance options.txt: %w", err)
+	}
+	key, found, err := parseResetKey(string(options))
+	if err != nil {
+		return 0, fmt.Errorf("options.txt: %w", err)
+	}
+	if !found {
+		return x11.KeyF6, nil
+	}
+	return key, nil
+}
+
+// parseResetKey finds the Atum reset key in the given options file contents.
+// It returns whether or not the key was present.
+func parseResetKey(options string) (xproto.Keycode, bool, error) {
+	for _, line := range strings.Split(options, "\n") {
 		// Only parse this keybind if it is the Atum reset key.
-		isResetKey := strings.Contains(line, "key_Create New World")
-		if !isResetKey {
+		if !strings.Contains(line, "key_Create New World") {
 			continue
 		}
 
 		// Parse the key.
-		keyName := strings.Split(line, ":")[1]
+		_, keyName, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			return 0, false, fmt.Errorf("invalid keybind line %q", line)
+		}
 		keyName = strings.TrimPrefix(keyName, "key.keyboard.")
 		if keyName == "unknown" {
-			return InstanceInfo{}, true, fmt.Errorf("atum's \"Create New World\" keybind was unbound (set it to any key)")
+			return 0, false, fmt.Errorf("atum's \"Create New World\" keybind was unbound (set it to any key)")
 		}
 		keycode, ok := x11.KeycodesMc[keyName]
 		if !ok {
-			return InstanceInfo{}, true, fmt.Errorf("atum's \"Create New World\" keybind was set to an unknown keycode %s", keyName)
+			return 0, false, fmt.Errorf("atum's \"Create New World\" keybind was set to an unknown keycode %s", keyName)
 		}
+		return keycode, true, nil
+	}
+	return 0, false, nil
+}
 
-		// Store it.
-		if isResetKey {
-			resetKey = keycode
+// readStandardOptions returns the contents of the instance's StandardSettings
+// configuration (config/standardoptions.txt), or an empty string if there is
+// none. If the file only contains the path to another file (as is the case
+// when StandardSettings is configured to use a global file), the contents of
+// that file are returned instead.
+func readStandardOptions(dir string) (string, error) {
+	contents, err := os.ReadFile(dir + "/config/standardoptions.txt")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
 		}
+		return "", err
 	}
-
-	return InstanceInfo{
-		pid,
-		win,
-		pwd,
-		version,
-		modernWp,
-		resetKey,
-	}, true, nil
+	path := strings.TrimSpace(string(contents))
+	if !strings.Contains(path, "\n") && strings.HasPrefix(path, "/") {
+		contents, err = os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read global file %q: %w", path, err)
+		}
+	}
+	return string(contents), nil
 }
 
 // hasModernWp determines whether or not the instance has a WorldPreview build
@@ -175,7 +268,7 @@ func hasModernWp(dir string) (bool, error) {
 
 // isMinecraftWindow determines whether or not the window is a Minecraft
 // window.
-func isMinecraftWindow(x *x11.Client, win xproto.Window) bool {
+func isMinecraftWindow(x x11.Conn, win xproto.Window) bool {
 	// Check that the window has "Minecraft" in its class.
 	//
 	// There are more checks which could be performed here (e.g. checking that
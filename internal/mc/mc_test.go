@@ -0,0 +1,265 @@
+package mc
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jezek/xgb/xproto"
+	"github.com/tesselslate/resetti/internal/log"
+	"github.com/tesselslate/resetti/internal/x11"
+	"github.com/tesselslate/resetti/internal/x11/x11test"
+)
+
+// TestMain sets up a logger for the package's code to log to. The logger is
+// not closed, since closing it removes the log configuration which tests in
+// other packages may be using at the same time.
+func TestMain(m *testing.M) {
+	_ = log.DefaultLogger(log.ERROR, "", true)
+	os.Exit(m.Run())
+}
+
+// writeFiles creates the given files (relative to dir) with the given
+// contents.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetResetKey(t *testing.T) {
+	global := filepath.Join(t.TempDir(), "standardoptions.txt")
+	writeFiles(t, filepath.Dir(global), map[string]string{
+		"standardoptions.txt": "key_Create New World:key.keyboard.h\n",
+	})
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		key   xproto.Keycode
+		err   bool
+	}{
+		{
+			name: "OptionsOnly",
+			files: map[string]string{
+				"options.txt": "key_key.attack:key.mouse.left\nkey_Create New World:key.keyboard.u\n",
+			},
+			key: x11.KeycodesMc["u"],
+		},
+		{
+			name: "StandardOverridesOptions",
+			files: map[string]string{
+				"options.txt":                "key_Create New World:key.keyboard.u\n",
+				"config/standardoptions.txt": "fov:0.0\nkey_Create New World:key.keyboard.f8\n",
+			},
+			key: x11.KeycodesMc["f8"],
+		},
+		{
+			name: "StandardOverridesBrokenOptions",
+			files: map[string]string{
+				"options.txt":                "key_Create New World:key.keyboard.unknown\n",
+				"config/standardoptions.txt": "key_Create New World:key.keyboard.f8\n",
+			},
+			key: x11.KeycodesMc["f8"],
+		},
+		{
+			name: "StandardWithoutKey",
+			files: map[string]string{
+				"options.txt":                "key_Create New World:key.keyboard.u\n",
+				"config/standardoptions.txt": "fov:0.0\nrenderDistance:2\n",
+			},
+			key: x11.KeycodesMc["u"],
+		},
+		{
+			name: "GlobalStandard",
+			files: map[string]string{
+				"options.txt":                "key_Create New World:key.keyboard.u\n",
+				"config/standardoptions.txt": global + "\r\n",
+			},
+			key: x11.KeycodesMc["h"],
+		},
+		{
+			name: "CRLF",
+			files: map[string]string{
+				"options.txt":                "key_Create New World:key.keyboard.u\r\n",
+				"config/standardoptions.txt": "fov:0.0\r\nkey_Create New World:key.keyboard.keypad.1\r\nrenderDistance:2\r\n",
+			},
+			key: x11.KeycodesMc["keypad.1"],
+		},
+		{
+			name: "DefaultF6",
+			files: map[string]string{
+				"options.txt": "key_key.attack:key.mouse.left\n",
+			},
+			key: x11.KeyF6,
+		},
+		{
+			name: "Unbound",
+			files: map[string]string{
+				"options.txt": "key_Create New World:key.keyboard.unknown\n",
+			},
+			err: true,
+		},
+		{
+			name: "BrokenStandard",
+			files: map[string]string{
+				"options.txt":                "key_Create New World:key.keyboard.u\n",
+				"config/standardoptions.txt": "key_Create New World:key.keyboard.not.a.key\n",
+			},
+			err: true,
+		},
+		{
+			name:  "NoOptions",
+			files: map[string]string{},
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			key, err := getResetKey(dir)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("got key %d, want error", key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("get reset key: %s", err)
+			}
+			if key != tt.key {
+				t.Errorf("got key %d, want %d", key, tt.key)
+			}
+		})
+	}
+}
+
+// The files of a usable instance.
+var usableInstance = map[string]string{
+	"mods/.keep":  "",
+	"options.txt": "key_Create New World:key.keyboard.u\n",
+}
+
+// startInstance creates an instance directory with the given files and starts
+// a long-running process in it to act as the instance. It returns the PID of
+// the process, which is killed when the test finishes.
+func startInstance(t *testing.T, files map[string]string) uint32 {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	cmd := exec.Command("sleep", "60")
+	cmd.Dir = dir
+	if err := cmd.Start(); err != nil {
+		t.Skipf("start sleep: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	return uint32(cmd.Process.Pid)
+}
+
+func TestFindInstance(t *testing.T) {
+	good := startInstance(t, usableInstance)
+	other := startInstance(t, usableInstance)
+	noMods := startInstance(t, map[string]string{
+		"options.txt": "key_Create New World:key.keyboard.u\n",
+	})
+	noOptions := startInstance(t, map[string]string{
+		"mods/.keep": "",
+	})
+
+	tests := []struct {
+		name string
+		pids []uint32 // PID for each window, starting from window 1
+		wid  xproto.Window
+		err  string // Part of the expected error, if any
+	}{
+		{"Single", []uint32{good}, 1, ""},
+		{"FirstOfSeveral", []uint32{other, good}, 1, ""},
+		{"DuplicateDirectory", []uint32{good, good}, 0, "multiple windows"},
+		{"UnusableAndGood", []uint32{noMods, good, noOptions}, 2, ""},
+		{"OnlyUnusable", []uint32{noMods, noOptions}, 0, "no usable instance"},
+		{"None", nil, 0, "no instance"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := x11test.NewConn()
+			x.AddWindow(100, x11test.Window{Class: "Firefox", Title: "Firefox"})
+			for i, pid := range tt.pids {
+				x.AddWindow(xproto.Window(i+1), x11test.Window{
+					Class: "Minecraft* 1.16.1",
+					Title: "Minecraft* 1.16.1",
+					Pid:   pid,
+				})
+			}
+			info, err := FindInstance(x)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got window %d and error %v, want error containing %q", info.Wid, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("find instance: %s", err)
+			}
+			if info.Wid != tt.wid {
+				t.Errorf("got window %d, want %d", info.Wid, tt.wid)
+			}
+		})
+	}
+}
+
+// TestFindInstanceDeadPid checks that windows left behind by an instance whose
+// process has exited are skipped, since their working directory cannot be
+// resolved.
+func TestFindInstanceDeadPid(t *testing.T) {
+	// Get the PID of a process which has already exited.
+	dead := exec.Command("true")
+	if err := dead.Run(); err != nil {
+		t.Skipf("run true: %s", err)
+	}
+
+	live := startInstance(t, usableInstance)
+
+	x := x11test.NewConn()
+	x.AddWindow(1, x11test.Window{
+		Class: "Minecraft* 1.16.1",
+		Title: "Minecraft* 1.16.1",
+		Pid:   uint32(dead.Process.Pid),
+	})
+	x.AddWindow(2, x11test.Window{
+		Class: "Minecraft* 1.16.1",
+		Title: "Minecraft* 1.16.1",
+		Pid:   live,
+	})
+
+	info, err := FindInstance(x)
+	if err != nil {
+		t.Fatalf("find instance: %s", err)
+	}
+	if info.Wid != 2 || info.Pid != live {
+		t.Errorf("got window %d (pid %d), want window 2 (pid %d)", info.Wid, info.Pid, live)
+	}
+
+	// With only the dead window left, no instance should be found.
+	x = x11test.NewConn()
+	x.AddWindow(1, x11test.Window{
+		Class: "Minecraft* 1.16.1",
+		Title: "Minecraft* 1.16.1",
+		Pid:   uint32(dead.Process.Pid),
+	})
+	if info, err := FindInstance(x); err == nil {
+		t.Errorf("got window %d, want error", info.Wid)
+	}
+}
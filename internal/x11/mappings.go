@@ -1,6 +1,10 @@
 package x11
 
-import "github.com/jezek/xgb/xproto"
+import (
+	"fmt"
+
+	"github.com/jezek/xgb/xproto"
+)
 
 // Buttons is a list of buttons used for config parsing.
 var Buttons = map[string]xproto.Button{
@@ -23,6 +27,14 @@ var Buttons = map[string]xproto.Button{
 	"m4":          xproto.ButtonIndex4,
 	"mouse5":      xproto.ButtonIndex5,
 	"m5":          xproto.ButtonIndex5,
+	"mouse6":      6,
+	"m6":          6,
+	"mouse7":      7,
+	"m7":          7,
+	"mouse8":      8,
+	"m8":          8,
+	"mouse9":      9,
+	"m9":          9,
 }
 
 // Keycodes is a list of keycodes used for config parsing.
@@ -219,3 +231,43 @@ var Modifiers = map[string]xproto.Keycode{
 	"rctrl":    105,
 	"rcontrol": 105,
 }
+
+// ButtonName returns a human-readable name for the given button.
+func ButtonName(button xproto.Button) string {
+	for name, b := range Buttons {
+		if b == button && isPreferredName(name, Buttons, button) {
+			return name
+		}
+	}
+	return fmt.Sprintf("button%d", button)
+}
+
+// KeyName returns a human-readable name for the given keycode. Modifier names
+// are preferred over regular key names.
+func KeyName(code xproto.Keycode) string {
+	for name, c := range Modifiers {
+		if c == code && isPreferredName(name, Modifiers, code) {
+			return name
+		}
+	}
+	for name, c := range Keycodes {
+		if c == code && isPreferredName(name, Keycodes, code) {
+			return name
+		}
+	}
+	return fmt.Sprintf("code%d", code)
+}
+
+// isPreferredName determines whether the given name is the preferred
+// (shortest, then alphabetically first) name for the given value.
+func isPreferredName[T comparable](name string, names map[string]T, value T) bool {
+	for other, v := range names {
+		if v != value || other == name {
+			continue
+		}
+		if len(other) < len(name) || len(other) == len(name) && other < name {
+			return false
+		}
+	}
+	return true
+}
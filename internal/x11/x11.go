@@ -24,6 +24,8 @@ const (
 	netWmDesktop      = "_NET_WM_DESKTOP"
 	netWmPid          = "_NET_WM_PID"
 	netWmName         = "_NET_WM_NAME"
+	netWmState        = "_NET_WM_STATE"
+	netWmStateFull    = "_NET_WM_STATE_FULLSCREEN"
 	utf8String        = "UTF8_STRING"
 	wmClass           = "WM_CLASS"
 	wmName            = "WM_NAME"
@@ -77,7 +79,9 @@ var (
 	errInvalidLength  = errors.New("invalid response length")
 )
 
-// Button -> mask mappings
+// Button -> mask mappings. The core protocol only reports the state of the
+// first 5 buttons. The state of any other buttons is tracked by grabbing them
+// (see GrabButton.)
 var masks = map[xproto.Button]uint16{
 	xproto.ButtonIndex1: xproto.ButtonMask1,
 	xproto.ButtonIndex2: xproto.ButtonMask2,
@@ -112,16 +116,51 @@ type Client struct {
 	// to ensure that resetti's inputs don't get dropped by GLFW.
 	lastKeyState map[xproto.Window]keyState
 
-	// The mutex guards lastKeyState and active.
+	// The state of any grabbed buttons which are not reported by QueryPointer.
+	// Each button's state is stored in the bit with the same index as the
+	// button.
+	grabbedButtons uint32
+
+	// The mutex guards lastKeyState, active, and grabbedButtons.
 	mu sync.Mutex
 }
 
+// Conn is the subset of Client's methods which resetti uses to query input
+// state and interact with instance windows. It allows other implementations
+// (e.g. a fake X server for testing) to be used in place of a Client.
+type Conn interface {
+	Click(xproto.Window)
+	FocusWindow(xproto.Window) error
+	GetActiveWindow() xproto.Window
+	GetLayoutKeycodes() (map[string]xproto.Keycode, error)
+	GetScreenSize(xproto.Window) (uint16, uint16, error)
+	GetWindowClass(xproto.Window) (string, error)
+	GetWindowList() []xproto.Window
+	GetWindowPid(xproto.Window) (uint32, error)
+	GetWindowSize(xproto.Window) (uint16, uint16, error)
+	GetWindowTitle(xproto.Window) (string, error)
+	GrabButton(xproto.Button) error
+	IsFullscreen(xproto.Window) (bool, error)
+	MoveWindow(win xproto.Window, x, y int32, w, h uint32)
+	Poll(context.Context) (<-chan Event, <-chan error, error)
+	QueryKeymap() (Keymap, error)
+	QueryPointer(xproto.Window) (Pointer, error)
+	SendKeyDown(xproto.Keycode, xproto.Window)
+	SendKeyPress(xproto.Keycode, xproto.Window)
+	SendKeyUp(xproto.Keycode, xproto.Window)
+	SetFullscreen(xproto.Window, bool) error
+}
+
 // Event represents an event from the X server to be processed by resetti.
 type Event any
 
 // FocusEvent represents a window focus change.
 type FocusEvent xproto.Window
 
+// MappingEvent represents a change in the keyboard mapping (e.g. the user
+// switched keyboard layouts.)
+type MappingEvent struct{}
+
 // InputState represents the state of a button or key (up or down.)
 type InputState int
 
@@ -138,6 +177,9 @@ type Pointer struct {
 
 	// Modmask (contains keyboard modifiers)
 	buttons uint16
+
+	// The state of grabbed buttons (see Client.grabbedButtons.)
+	grabbed uint32
 }
 
 // atomCache maintains a mapping of strings to X11 atoms to avoid re-requesting
@@ -161,21 +203,27 @@ type rawEvent interface {
 	Bytes() []byte
 }
 
-// NewClient attempts to create a new Client.
-func NewClient() (Client, error) {
-	conn, err := xgb.NewConn()
+// NewClient attempts to create a new Client connected to the given X display
+// (e.g. ":0.1".) If the display is empty, $DISPLAY is used.
+func NewClient(display string) (Client, error) {
+	conn, err := xgb.NewConnDisplay(display)
 	if err != nil {
 		return Client{}, err
 	}
 	root := xproto.Setup(conn).DefaultScreen(conn).Root
-	err = xproto.ChangeWindowAttributesChecked(
-		conn,
-		root,
-		xproto.CwEventMask,
-		[]uint32{maskProperty},
-	).Check()
-	if err != nil {
-		return Client{}, err
+
+	// Listen for property changes on the root window of every screen, so that
+	// focus changes are noticed regardless of which screen they occur on.
+	for _, screen := range xproto.Setup(conn).Roots {
+		err = xproto.ChangeWindowAttributesChecked(
+			conn,
+			screen.Root,
+			xproto.CwEventMask,
+			[]uint32{maskProperty},
+		).Check()
+		if err != nil {
+			return Client{}, err
+		}
 	}
 	offset, err := approximateOffset(conn)
 	if err != nil {
@@ -191,6 +239,7 @@ func NewClient() (Client, error) {
 		0,
 		offset,
 		make(map[xproto.Window]keyState),
+		0,
 		sync.Mutex{},
 	}, nil
 }
@@ -245,12 +294,13 @@ func (c *Client) Click(win xproto.Window) {
 
 // FocusWindow activates the given window.
 func (c *Client) FocusWindow(win xproto.Window) error {
-	winDesktop, err := c.getPropertyInt(c.root, netWmDesktop, xproto.AtomCardinal)
+	root := c.windowRoot(win)
+	winDesktop, err := c.getPropertyInt(root, netWmDesktop, xproto.AtomCardinal)
 	switch err {
 	case errInvalidLength:
 		break
 	case nil:
-		if err = c.setCurrentDesktop(winDesktop); err != nil {
+		if err = c.setCurrentDesktop(root, winDesktop); err != nil {
 			return fmt.Errorf("set current desktop: %w", err)
 		}
 	default:
@@ -268,7 +318,7 @@ func (c *Client) FocusWindow(win xproto.Window) error {
 		Type:   activeWindow,
 		Data:   xproto.ClientMessageDataUnionData32New(data),
 	}
-	c.sendEvent(evt, maskSubstructure, c.root)
+	c.sendEvent(evt, maskSubstructure, root)
 	return nil
 }
 
@@ -284,14 +334,36 @@ func (c *Client) GetCurrentTime() uint32 {
 	return uint32(time.Now().UnixMilli() - int64(c.timeOffset))
 }
 
+// GetLayoutKeycodes returns the keycode of each layout-dependent key (letters
+// and numbers) in Keycodes according to the current keyboard mapping. Keys
+// which are not present in the current layout are omitted.
+func (c *Client) GetLayoutKeycodes() (map[string]xproto.Keycode, error) {
+	setup := xproto.Setup(c.conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	reply, err := xproto.GetKeyboardMapping(c.conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return nil, err
+	}
+	return layoutKeycodes(setup.MinKeycode, reply.KeysymsPerKeycode, reply.Keysyms), nil
+}
+
 // GetRootWindow returns the ID of the root window.
 func (c *Client) GetRootWindow() xproto.Window {
 	return c.root
 }
 
-// GetWindowList returns a list of all open windows.
+// GetScreenSize returns the size of the screen which the given window is on.
+func (c *Client) GetScreenSize(win xproto.Window) (uint16, uint16, error) {
+	return c.GetWindowSize(c.windowRoot(win))
+}
+
+// GetWindowList returns a list of all open windows on every screen.
 func (c *Client) GetWindowList() []xproto.Window {
-	return c.GetWindowChildren(c.root)
+	var windows []xproto.Window
+	for _, screen := range xproto.Setup(c.conn).Roots {
+		windows = append(windows, c.GetWindowChildren(screen.Root)...)
+	}
+	return windows
 }
 
 // GetWindowChildren returns a list of all child windows (and their children,
@@ -341,6 +413,31 @@ func (c *Client) GetWindowTitle(win xproto.Window) (string, error) {
 	return c.getPropertyUtf8(win, netWmName)
 }
 
+// GrabButton grabs the given mouse button on the root window of every screen
+// so that its state can be tracked. Presses of the button will no longer be
+// sent to other applications. This is only needed for buttons 6-9, which
+// QueryPointer does not report.
+func (c *Client) GrabButton(button xproto.Button) error {
+	for _, screen := range xproto.Setup(c.conn).Roots {
+		err := xproto.GrabButtonChecked(
+			c.conn,
+			false,
+			screen.Root,
+			uint16(maskButton),
+			xproto.GrabModeAsync,
+			xproto.GrabModeAsync,
+			xproto.WindowNone,
+			xproto.CursorNone,
+			byte(button),
+			xproto.ModMaskAny,
+		).Check()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GrabPointer grabs the mouse pointer, diverting all mouse events to resetti.
 func (c *Client) GrabPointer(win xproto.Window, confine bool) error {
 	confineTo := c.root
@@ -368,6 +465,24 @@ func (c *Client) GrabPointer(win xproto.Window, confine bool) error {
 	}
 }
 
+// IsFullscreen returns whether or not the given window is fullscreen.
+func (c *Client) IsFullscreen(win xproto.Window) (bool, error) {
+	fullscreen, err := c.atoms.Get(netWmStateFull)
+	if err != nil {
+		return false, fmt.Errorf("get _NET_WM_STATE_FULLSCREEN atom: %w", err)
+	}
+	reply, err := c.getProperty(win, netWmState, xproto.AtomAtom)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i+4 <= len(reply); i += 4 {
+		if xproto.Atom(binary.LittleEndian.Uint32(reply[i:])) == fullscreen {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // MoveWindow moves and resizes the given window.
 func (c *Client) MoveWindow(win xproto.Window, x, y int32, w, h uint32) {
 	xproto.ConfigureWindow(
@@ -404,11 +519,15 @@ func (c *Client) QueryPointer(win xproto.Window) (Pointer, error) {
 	if err != nil {
 		return Pointer{}, err
 	}
+	c.mu.Lock()
+	grabbed := c.grabbedButtons
+	c.mu.Unlock()
 	p := Pointer{
 		int(reply.RootX), int(reply.RootY),
 		int(reply.WinX), int(reply.WinY),
 		reply.Child,
 		reply.Mask,
+		grabbed,
 	}
 	return p, nil
 }
@@ -430,6 +549,35 @@ func (c *Client) SendKeyUp(code xproto.Keycode, win xproto.Window) {
 	c.sendKeyEvent(code, StateUp, win)
 }
 
+// SetFullscreen asks the window manager to make the given window fullscreen
+// or to exit fullscreen.
+func (c *Client) SetFullscreen(win xproto.Window, fullscreen bool) error {
+	state, err := c.atoms.Get(netWmState)
+	if err != nil {
+		return fmt.Errorf("get _NET_WM_STATE atom: %w", err)
+	}
+	full, err := c.atoms.Get(netWmStateFull)
+	if err != nil {
+		return fmt.Errorf("get _NET_WM_STATE_FULLSCREEN atom: %w", err)
+	}
+	data := make([]uint32, 5)
+	if fullscreen {
+		data[0] = 1 // _NET_WM_STATE_ADD
+	} else {
+		data[0] = 0 // _NET_WM_STATE_REMOVE
+	}
+	data[1] = uint32(full)
+	data[3] = 1 // Source indicator (1 = application)
+	evt := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: win,
+		Type:   state,
+		Data:   xproto.ClientMessageDataUnionData32New(data),
+	}
+	c.sendEvent(evt, maskSubstructure, c.windowRoot(win))
+	return nil
+}
+
 // UngrabPointer ungrabs the mouse pointer.
 func (c *Client) UngrabPointer() error {
 	return xproto.UngrabPointerChecked(c.conn, xproto.TimeCurrentTime).Check()
@@ -440,9 +588,10 @@ func (c *Client) WarpPointer(x, y int, dest xproto.Window) {
 	xproto.WarpPointer(c.conn, xproto.WindowNone, dest, 0, 0, 0, 0, int16(x), int16(y))
 }
 
-// getActiveWindow returns the currently focused window.
-func (c *Client) getActiveWindow() (uint32, error) {
-	win, err := c.getPropertyInt(c.root, netActiveWindow, xproto.AtomWindow)
+// getActiveWindow returns the currently focused window on the screen with the
+// given root window.
+func (c *Client) getActiveWindow(root xproto.Window) (uint32, error) {
+	win, err := c.getPropertyInt(root, netActiveWindow, xproto.AtomWindow)
 	if err != nil {
 		// The _NET_ACTIVE_WINDOW property might not exist depending on the
 		// window manager.
@@ -541,20 +690,7 @@ func (c *Client) sendKeyEvent(key xproto.Keycode, state InputState, win xproto.W
 	// https://github.com/glfw/glfw/blob/3.3.8/src/x11_window.c#L1260
 	// https://github.com/glfw/glfw/blob/3.3.8/src/x11_window.c#L1359
 
-	c.mu.Lock()
-	lastState, ok := c.lastKeyState[win]
-	time := c.GetCurrentTime() + 15
-	if ok {
-		if lastState.time >= time {
-			time = lastState.time + 1
-		}
-		if lastState.code == key {
-			time = lastState.time + 20
-		}
-	}
-	c.lastKeyState[win] = keyState{time, key}
-	c.mu.Unlock()
-
+	time := c.nextKeyTime(key, win)
 	evt := xproto.KeyPressEvent{
 		Detail:     key,
 		Time:       xproto.Timestamp(time),
@@ -571,8 +707,8 @@ func (c *Client) sendKeyEvent(key xproto.Keycode, state InputState, win xproto.W
 }
 
 // setCurrentDesktop attempts to upadte the current desktop by setting the
-// _NET_CURRENT_DESKTOP property of the root window to the given desktop.
-func (c *Client) setCurrentDesktop(desktop uint32) error {
+// _NET_CURRENT_DESKTOP property of the given root window to the given desktop.
+func (c *Client) setCurrentDesktop(root xproto.Window, desktop uint32) error {
 	// Get the _NET_CURRENT_DESKTOP atom.
 	currentDesktop, err := c.atoms.Get(netCurrentDesktop)
 	if err != nil {
@@ -584,14 +720,86 @@ func (c *Client) setCurrentDesktop(desktop uint32) error {
 	data[0] = desktop
 	evt := xproto.ClientMessageEvent{
 		Format: 32,
-		Window: c.root,
+		Window: root,
 		Type:   currentDesktop,
 		Data:   xproto.ClientMessageDataUnionData32New(data),
 	}
-	c.sendEvent(evt, maskSubstructure, c.root)
+	c.sendEvent(evt, maskSubstructure, root)
 	return nil
 }
 
+// windowRoot returns the root window of the screen which the given window is
+// on. If the root window cannot be determined, the default root is returned.
+func (c *Client) windowRoot(win xproto.Window) xproto.Window {
+	tree, err := xproto.QueryTree(c.conn, win).Reply()
+	if err != nil {
+		return c.root
+	}
+	return tree.Root
+}
+
+// layoutKeycodes returns the keycode of each layout-dependent key in Keycodes
+// according to the given keyboard mapping, which lists the keysyms produced by
+// each keycode starting from minCode.
+func layoutKeycodes(minCode xproto.Keycode, perKeycode byte, keysyms []xproto.Keysym) map[string]xproto.Keycode {
+	// Find the first keycode which produces each keysym. Keysyms from lower
+	// shift levels are preferred, so that e.g. the unshifted "1" key is
+	// picked over a key which produces "1" when shifted.
+	per := int(perKeycode)
+	codes := make(map[xproto.Keysym]xproto.Keycode)
+	for level := 0; level < per; level += 1 {
+		for i := 0; i*per+level < len(keysyms); i += 1 {
+			sym := keysyms[i*per+level]
+			if _, ok := codes[sym]; !ok {
+				codes[sym] = minCode + xproto.Keycode(i)
+			}
+		}
+	}
+
+	layout := make(map[string]xproto.Keycode)
+	for name := range Keycodes {
+		if len(name) != 1 {
+			continue
+		}
+		// The keysyms for Latin letters and numbers match their ASCII values.
+		if code, ok := codes[xproto.Keysym(name[0])]; ok {
+			layout[name] = code
+		}
+	}
+	return layout
+}
+
+// nextKeyTime returns the timestamp to use for the next key event with the
+// given key sent to the given window, and records it as the last key event
+// for that window. See sendKeyEvent.
+func (c *Client) nextKeyTime(key xproto.Keycode, win xproto.Window) uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lastState, ok := c.lastKeyState[win]
+	time := c.GetCurrentTime() + 15
+	if ok {
+		if lastState.time >= time {
+			time = lastState.time + 1
+		}
+		if lastState.code == key && time < lastState.time+20 {
+			time = lastState.time + 20
+		}
+	}
+	c.lastKeyState[win] = keyState{time, key}
+	return time
+}
+
+// setGrabbedButton updates the state of a grabbed button.
+func (c *Client) setGrabbedButton(button xproto.Button, state InputState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if state == StateDown {
+		c.grabbedButtons |= grabbedMask(button)
+	} else {
+		c.grabbedButtons &^= grabbedMask(button)
+	}
+}
+
 // poll listens for user inputs in the background.
 func (c *Client) poll(ctx context.Context, ch chan<- Event, errch chan<- error) {
 	defer close(ch)
@@ -622,12 +830,20 @@ func (c *Client) poll(ctx context.Context, ch chan<- Event, errch chan<- error)
 			if activeWindow != evt.Atom {
 				continue
 			}
-			win, err := c.getActiveWindow()
+			win, err := c.getActiveWindow(evt.Window)
 			if err != nil {
 				errch <- err
 				continue
 			}
 			ch <- FocusEvent(win)
+		case xproto.ButtonPressEvent:
+			c.setGrabbedButton(evt.Detail, StateDown)
+		case xproto.ButtonReleaseEvent:
+			c.setGrabbedButton(evt.Detail, StateUp)
+		case xproto.MappingNotifyEvent:
+			if evt.Request == xproto.MappingKeyboard {
+				ch <- MappingEvent{}
+			}
 		}
 	}
 }
@@ -646,7 +862,18 @@ func (k *Keymap) HasPressed(mask [32]byte) bool {
 // HasPressed determines whether all of the given buttons are pressed in the
 // keymap.
 func (p *Pointer) HasPressed(button xproto.Button) bool {
-	return p.buttons&masks[button] != 0
+	if mask, ok := masks[button]; ok {
+		return p.buttons&mask != 0
+	}
+	return p.grabbed&grabbedMask(button) != 0
+}
+
+// grabbedMask returns the mask for the given button in a grabbed button state.
+func grabbedMask(button xproto.Button) uint32 {
+	if button >= 32 {
+		return 0
+	}
+	return 1 << button
 }
 
 // approximateOffset attempts to find the offset between the system clock and
@@ -0,0 +1,127 @@
+package x11
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+func TestLayoutKeycodes(t *testing.T) {
+	// A small AZERTY keyboard mapping with two keysyms per keycode. The
+	// number row produces symbols unless shift is held.
+	const minCode = 8
+	mapping := map[xproto.Keycode][2]xproto.Keysym{
+		9:  {0xff1b, 'a'}, // Escape, with "a" on a (made up) shifted level
+		10: {'&', '1'},
+		11: {0xe9, '2'}, // eacute
+		24: {'a', 'A'},
+		25: {'z', 'Z'},
+		27: {'r', 'R'},
+		38: {'q', 'Q'},
+		47: {'m', 'M'},
+		52: {'w', 'W'},
+		58: {',', '?'},
+	}
+	keysyms := make([]xproto.Keysym, 2*(60-minCode))
+	for code, syms := range mapping {
+		copy(keysyms[2*(code-minCode):], syms[:])
+	}
+
+	layout := layoutKeycodes(minCode, 2, keysyms)
+	want := map[string]xproto.Keycode{
+		"1": 10,
+		"2": 11,
+		"a": 24,
+		"m": 47,
+		"q": 38,
+		"r": 27,
+		"w": 52,
+		"z": 25,
+	}
+	for name, code := range want {
+		if layout[name] != code {
+			t.Errorf("key %q: got keycode %d, want %d", name, layout[name], code)
+		}
+	}
+	if code, ok := layout["3"]; ok {
+		t.Errorf("key \"3\" is not in the layout but got keycode %d", code)
+	}
+}
+
+func TestPointerHasPressed(t *testing.T) {
+	// Bits 13-14 of the core mask hold the XKB keyboard group, and must not be
+	// mistaken for buttons.
+	const group = 1<<13 | 1<<14
+
+	c := Client{}
+	c.setGrabbedButton(6, StateDown)
+	c.setGrabbedButton(9, StateDown)
+	c.setGrabbedButton(9, StateUp)
+	p := Pointer{
+		buttons: xproto.ButtonMask1 | xproto.ButtonMask4 | group,
+		grabbed: c.grabbedButtons,
+	}
+
+	tests := []struct {
+		button  xproto.Button
+		pressed bool
+	}{
+		{1, true},
+		{2, false},
+		{3, false},
+		{4, true},
+		{5, false},
+		{6, true},
+		{7, false},
+		{8, false},
+		{9, false},
+	}
+	for _, tt := range tests {
+		if got := p.HasPressed(tt.button); got != tt.pressed {
+			t.Errorf("button %d: got pressed %t, want %t", tt.button, got, tt.pressed)
+		}
+	}
+}
+
+func TestGrabbedMask(t *testing.T) {
+	for button := xproto.Button(6); button <= 9; button += 1 {
+		mask := grabbedMask(button)
+		if mask != 1<<button {
+			t.Errorf("button %d: got mask %b, want %b", button, mask, 1<<button)
+		}
+	}
+	if mask := grabbedMask(40); mask != 0 {
+		t.Errorf("button 40: got mask %b, want 0", mask)
+	}
+}
+
+func TestNextKeyTime(t *testing.T) {
+	const win = xproto.Window(1)
+	c := Client{lastKeyState: make(map[xproto.Window]keyState)}
+
+	// A key press (down and up) with the same key must be at least 20ms
+	// apart.
+	down := c.nextKeyTime(KeyF6, win)
+	up := c.nextKeyTime(KeyF6, win)
+	if up-down != 20 {
+		t.Errorf("press: got key up %dms after key down, want 20ms", up-down)
+	}
+
+	// Any other key must come after the last one.
+	other := c.nextKeyTime(KeyF3, win)
+	if other <= up {
+		t.Errorf("got time %d for next key, want more than %d", other, up)
+	}
+
+	// A held key must be released at least as long after it was pressed as
+	// it was held for. This uses another window, since the events above are
+	// ahead of the current time.
+	const hold = 50
+	down = c.nextKeyTime(KeyF6, win+1)
+	time.Sleep((hold + 1) * time.Millisecond)
+	up = c.nextKeyTime(KeyF6, win+1)
+	if up-down < hold {
+		t.Errorf("hold: got key up %dms after key down, want at least %dms", up-down, hold)
+	}
+}
@@ -0,0 +1,266 @@
+// Package x11test provides a fake X server connection for testing code which
+// uses x11.Conn.
+package x11test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+	"github.com/tesselslate/resetti/internal/x11"
+)
+
+// Request types
+const (
+	OpClick = iota
+	OpFocus
+	OpKeyDown
+	OpKeyPress
+	OpKeyUp
+	OpMove
+	OpFullscreen
+)
+
+var _ x11.Conn = (*Conn)(nil)
+
+// Call is a single request made to a Conn.
+type Call struct {
+	Op   int
+	Win  xproto.Window
+	Key  xproto.Keycode
+	X, Y int32
+	W, H uint32
+	Full bool
+
+	// The time at which the request was made.
+	Time time.Time
+}
+
+// Window contains the state of a window in a Conn.
+type Window struct {
+	Class, Title string
+	Pid          uint32
+	W, H         uint16
+	Fullscreen   bool
+
+	// Whether the window ignores requests to change its fullscreen state.
+	Stuck bool
+}
+
+// Conn is a fake X server which records the requests made to it. It
+// implements x11.Conn.
+type Conn struct {
+	mu sync.Mutex
+
+	calls   []Call
+	windows map[xproto.Window]*Window
+	order   []xproto.Window
+	active  xproto.Window
+
+	screenW, screenH uint16
+}
+
+// NewConn creates a new Conn with a 1920x1080 screen and no windows.
+func NewConn() *Conn {
+	return &Conn{
+		windows: make(map[xproto.Window]*Window),
+		screenW: 1920,
+		screenH: 1080,
+	}
+}
+
+// AddWindow adds a window to the fake X server.
+func (f *Conn) AddWindow(win xproto.Window, window Window) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.windows[win] = &window
+	f.order = append(f.order, win)
+}
+
+// Calls returns the requests made so far.
+func (f *Conn) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Reset forgets all of the requests made so far.
+func (f *Conn) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+func (f *Conn) record(call Call) {
+	call.Time = time.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *Conn) window(win xproto.Window) (*Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	window, ok := f.windows[win]
+	if !ok {
+		return nil, errors.New("bad window")
+	}
+	return window, nil
+}
+
+// Click implements x11.Conn.
+func (f *Conn) Click(win xproto.Window) {
+	f.record(Call{Op: OpClick, Win: win})
+}
+
+// FocusWindow implements x11.Conn.
+func (f *Conn) FocusWindow(win xproto.Window) error {
+	f.record(Call{Op: OpFocus, Win: win})
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.active = win
+	return nil
+}
+
+// GetActiveWindow implements x11.Conn.
+func (f *Conn) GetActiveWindow() xproto.Window {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+// GetLayoutKeycodes implements x11.Conn.
+func (f *Conn) GetLayoutKeycodes() (map[string]xproto.Keycode, error) {
+	return map[string]xproto.Keycode{}, nil
+}
+
+// GetScreenSize implements x11.Conn.
+func (f *Conn) GetScreenSize(win xproto.Window) (uint16, uint16, error) {
+	if _, err := f.window(win); err != nil {
+		return 0, 0, err
+	}
+	return f.screenW, f.screenH, nil
+}
+
+// GetWindowClass implements x11.Conn.
+func (f *Conn) GetWindowClass(win xproto.Window) (string, error) {
+	window, err := f.window(win)
+	if err != nil {
+		return "", err
+	}
+	return window.Class, nil
+}
+
+// GetWindowList implements x11.Conn.
+func (f *Conn) GetWindowList() []xproto.Window {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]xproto.Window(nil), f.order...)
+}
+
+// GetWindowPid implements x11.Conn.
+func (f *Conn) GetWindowPid(win xproto.Window) (uint32, error) {
+	window, err := f.window(win)
+	if err != nil {
+		return 0, err
+	}
+	return window.Pid, nil
+}
+
+// GetWindowSize implements x11.Conn.
+func (f *Conn) GetWindowSize(win xproto.Window) (uint16, uint16, error) {
+	window, err := f.window(win)
+	if err != nil {
+		return 0, 0, err
+	}
+	return window.W, window.H, nil
+}
+
+// GetWindowTitle implements x11.Conn.
+func (f *Conn) GetWindowTitle(win xproto.Window) (string, error) {
+	window, err := f.window(win)
+	if err != nil {
+		return "", err
+	}
+	return window.Title, nil
+}
+
+// GrabButton implements x11.Conn.
+func (f *Conn) GrabButton(xproto.Button) error {
+	return nil
+}
+
+// IsFullscreen implements x11.Conn.
+func (f *Conn) IsFullscreen(win xproto.Window) (bool, error) {
+	window, err := f.window(win)
+	if err != nil {
+		return false, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return window.Fullscreen, nil
+}
+
+// MoveWindow implements x11.Conn.
+func (f *Conn) MoveWindow(win xproto.Window, x, y int32, w, h uint32) {
+	f.record(Call{Op: OpMove, Win: win, X: x, Y: y, W: w, H: h})
+	if window, err := f.window(win); err == nil {
+		f.mu.Lock()
+		window.W, window.H = uint16(w), uint16(h)
+		f.mu.Unlock()
+	}
+}
+
+// Poll implements x11.Conn.
+func (f *Conn) Poll(ctx context.Context) (<-chan x11.Event, <-chan error, error) {
+	ch := make(chan x11.Event)
+	errch := make(chan error)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+		close(errch)
+	}()
+	return ch, errch, nil
+}
+
+// QueryKeymap implements x11.Conn.
+func (f *Conn) QueryKeymap() (x11.Keymap, error) {
+	return x11.Keymap{}, nil
+}
+
+// QueryPointer implements x11.Conn.
+func (f *Conn) QueryPointer(xproto.Window) (x11.Pointer, error) {
+	return x11.Pointer{}, nil
+}
+
+// SendKeyDown implements x11.Conn.
+func (f *Conn) SendKeyDown(code xproto.Keycode, win xproto.Window) {
+	f.record(Call{Op: OpKeyDown, Win: win, Key: code})
+}
+
+// SendKeyPress implements x11.Conn.
+func (f *Conn) SendKeyPress(code xproto.Keycode, win xproto.Window) {
+	f.record(Call{Op: OpKeyPress, Win: win, Key: code})
+}
+
+// SendKeyUp implements x11.Conn.
+func (f *Conn) SendKeyUp(code xproto.Keycode, win xproto.Window) {
+	f.record(Call{Op: OpKeyUp, Win: win, Key: code})
+}
+
+// SetFullscreen implements x11.Conn.
+func (f *Conn) SetFullscreen(win xproto.Window, fullscreen bool) error {
+	f.record(Call{Op: OpFullscreen, Win: win, Full: fullscreen})
+	window, err := f.window(win)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !window.Stuck {
+		window.Fullscreen = fullscreen
+	}
+	return nil
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"bufio"
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/tesselslate/resetti/internal/cfg"
 	"github.com/tesselslate/resetti/internal/ctl"
 	"github.com/tesselslate/resetti/internal/log"
 	"github.com/tesselslate/resetti/internal/res"
+	"golang.org/x/sys/unix"
 )
 
 //go:embed .notice
@@ -36,8 +42,18 @@ func main() {
 		os.Exit(1)
 	}
 	if len(os.Args) < 2 {
-		printHelp()
-		os.Exit(1)
+		// Show the profile menu if running interactively.
+		if _, err := unix.IoctlGetTermios(int(os.Stdin.Fd()), unix.TCGETS); err != nil {
+			printHelp()
+			os.Exit(1)
+		}
+		profileName, err := pickProfile()
+		if err != nil {
+			logger.Error("Failed to pick profile: %s", err)
+			os.Exit(1)
+		}
+		Run(profileName)
+		return
 	}
 
 	switch os.Args[1] {
@@ -61,6 +77,15 @@ func main() {
 		} else {
 			logger.Info("Created profile!")
 		}
+	case "keys":
+		if len(os.Args) < 3 {
+			printHelp()
+			os.Exit(1)
+		}
+		if err := printKeys(os.Args[2]); err != nil {
+			logger.Error("Failed to print keybinds: %s", err)
+			os.Exit(1)
+		}
 	case "-d", "--debug":
 		logger.Info("Running in debug mode.")
 		logger.SetLevel(log.DEBUG)
@@ -71,6 +96,20 @@ func main() {
 		}
 		profileName := os.Args[2]
 		Run(profileName)
+	case "-p", "--profile":
+		if len(os.Args) < 3 {
+			logger.Error("Expected profile name after -p, --profile.")
+			printHelp()
+			os.Exit(1)
+		}
+		if len(os.Args) >= 4 {
+			if os.Args[3] == "-d" || os.Args[3] == "--debug" {
+				logger.Info("Running in debug mode.")
+				logger.SetLevel(log.DEBUG)
+			}
+		}
+		profileName := os.Args[2]
+		Run(profileName)
 	default:
 		if len(os.Args) >= 3 {
 			if os.Args[2] == "-d" || os.Args[2] == "--debug" {
@@ -96,11 +135,91 @@ func Run(profileName string) {
 	}
 }
 
+// pickProfile shows a menu of the user's configuration profiles and returns
+// the name of the one they pick.
+func pickProfile() (string, error) {
+	profiles, err := cfg.GetProfiles()
+	if err != nil {
+		return "", err
+	}
+	if len(profiles) == 0 {
+		return "", errors.New("no profiles found (create one with `resetti new`)")
+	}
+	fmt.Println("Profiles:")
+	for i, name := range profiles {
+		fmt.Printf("  %d. %s\n", i+1, name)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("Select a profile: ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimSpace(line)
+		if num, err := strconv.Atoi(line); err == nil && num >= 1 && num <= len(profiles) {
+			return profiles[num-1], nil
+		}
+		for _, name := range profiles {
+			if name == line {
+				return name, nil
+			}
+		}
+		fmt.Printf("Unknown profile %q.\n", line)
+	}
+}
+
+// printKeys prints a table of the keybinds in the given profile and the
+// actions they trigger.
+func printKeys(profileName string) error {
+	profile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return fmt.Errorf("get profile: %w", err)
+	}
+	binds := make([]cfg.Bind, 0, len(profile.Keybinds))
+	for bind := range profile.Keybinds {
+		binds = append(binds, bind)
+	}
+	sort.Slice(binds, func(i, j int) bool {
+		return binds[i].Describe() < binds[j].Describe()
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "BIND\tKEYS\tACTIONS")
+	seen := make(map[string]string)
+	var conflicts []string
+	for _, bind := range binds {
+		var actions []string
+		for _, action := range profile.Keybinds[bind].IngameActions {
+			actions = append(actions, action.String())
+		}
+		desc := bind.Describe()
+		fmt.Fprintf(w, "%s\t%s\t%s\n", bind.String(), desc, strings.Join(actions, ", "))
+		if other, ok := seen[desc]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%q and %q are the same bind (%s)", other, bind.String(), desc))
+		}
+		seen[desc] = bind.String()
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		fmt.Println("Conflict:", conflict)
+	}
+	return nil
+}
+
 func printHelp() {
 	fmt.Println(`
     resetti - Minecraft resetting macro
     USAGE:
+        resetti                 Pick a profile from a menu and run resetti.
+                                If stdin is not a terminal, this message is
+                                printed instead. Scripts should use -p to
+                                skip the menu.
         resetti [PROFILE]       Run resetti with the given profile.
+        resetti -p [PROFILE]    Run resetti with the given profile.
+          --profile [PROFILE]
           --force-log           Force the latest.log reader to be used.
           --force-wpstate       Force the wpstateout.txt reader to be used.
           -d, --debug           Run resetti in debug mode.
@@ -108,6 +227,7 @@ func printHelp() {
     SUBCOMMANDS:
         resetti new [PROFILE]   Create a new profile named PROFILE with
                                 the default configuration.
+        resetti keys [PROFILE]  Print the keybinds configured in PROFILE.
         resetti help            Print this message.
         resetti version         Get the version of resetti installed.
     `)